@@ -0,0 +1,110 @@
+// Package config loads the optional vbs settings file, ~/.config/vbs/config.json.
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Config holds user settings. Every field is optional; a missing file
+// yields the zero Config.
+type Config struct {
+	// AiderRoots lists directories searched for Aider chat histories, in
+	// addition to the working directories of other sessions. Each root is
+	// checked itself and one level of subdirectories deep.
+	AiderRoots []string `json:"aider_roots"`
+
+	// ClaudeRoots and CodexRoots list extra data directories to scan, laid
+	// out like ~/.claude and ~/.codex, e.g. a synced copy of another
+	// machine's sessions. They are scanned after the CLI's own directory.
+	ClaudeRoots []string `json:"claude_roots"`
+	CodexRoots  []string `json:"codex_roots"`
+
+	// Prices extends or overrides the built-in price table. Keys are model
+	// name prefixes; the longest matching prefix wins.
+	Prices map[string]Price `json:"prices"`
+}
+
+// Price is the cost of a model in USD per million tokens.
+type Price struct {
+	Input      float64 `json:"input"`
+	Output     float64 `json:"output"`
+	CacheRead  float64 `json:"cache_read"`
+	CacheWrite float64 `json:"cache_write"`
+}
+
+// Path returns the location of the config file.
+func Path() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "vbs", "config.json")
+}
+
+// Load reads the config file. A missing file is not an error.
+func Load() (Config, error) {
+	var cfg Config
+	data, err := os.ReadFile(Path())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// ExpandHome replaces a leading "~" in path with the user's home directory.
+func ExpandHome(path string) string {
+	if path == "~" || len(path) > 1 && path[:2] == "~/" {
+		if home, err := os.UserHomeDir(); err == nil {
+			return home + path[1:]
+		}
+	}
+	return path
+}
+
+// ClaudeHome is Claude Code's own data directory: $CLAUDE_CONFIG_DIR if
+// set, otherwise ~/.claude.
+func ClaudeHome() string {
+	if dir := os.Getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+		return dir
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".claude")
+}
+
+// CodexHome is Codex's own data directory: $CODEX_HOME if set, otherwise
+// ~/.codex.
+func CodexHome() string {
+	if dir := os.Getenv("CODEX_HOME"); dir != "" {
+		return dir
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".codex")
+}
+
+// Roots returns primary followed by the extra directories, expanded and
+// without duplicates.
+func Roots(primary string, extra []string) []string {
+	var roots []string
+	for _, root := range append([]string{primary}, extra...) {
+		if root == "" {
+			continue
+		}
+		root = filepath.Clean(ExpandHome(root))
+		if !slices.Contains(roots, root) {
+			roots = append(roots, root)
+		}
+	}
+	return roots
+}
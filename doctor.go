@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jackwu/vibesession/model"
+	"github.com/jackwu/vibesession/source"
+	"github.com/jackwu/vibesession/tts"
+)
+
+// doctor prints health checks in the style of "vbs tts setup" and counts
+// the ones that failed.
+type doctor struct {
+	problems int
+}
+
+func (d *doctor) check(c tts.Check) {
+	if !c.OK {
+		d.problems++
+		fmt.Printf("[fail] %s\n", c.Label)
+		if c.Fix != "" {
+			fmt.Printf("  %s\n", c.Fix)
+		}
+		return
+	}
+	fmt.Printf("[ok] %s\n", c.Label)
+}
+
+// runDoctor checks the data directories, reports how many sessions each
+// source found and which transcript files were skipped, grouped by why,
+// and checks the TTS install. It exits non-zero if anything needs fixing.
+func runDoctor(all []model.Session, diags []model.Diagnostic) {
+	var d doctor
+
+	fmt.Println("Data")
+	counts := make(map[model.Source]int)
+	perRoot := make(map[string]int)
+	for _, s := range all {
+		counts[s.Source]++
+		perRoot[string(s.Source)+"\x00"+s.Root]++
+	}
+	found := 0
+	for _, p := range source.All() {
+		rs, ok := p.(source.RootScanner)
+		if !ok {
+			continue
+		}
+		for _, root := range rs.Roots() {
+			_, err := os.ReadDir(root)
+			switch {
+			case os.IsNotExist(err):
+				fmt.Printf("[--] %s: %s not found\n", p.Label(), root)
+			case err != nil:
+				d.check(tts.Check{Label: fmt.Sprintf("%s: %v", p.Label(), err), Fix: "Check the permissions: ls -ld " + root})
+			default:
+				found++
+				n := perRoot[string(p.Source())+"\x00"+root]
+				d.check(tts.Check{OK: true, Label: fmt.Sprintf("%s: %s (%d sessions)", p.Label(), root, n)})
+			}
+		}
+	}
+	switch {
+	case found == 0 && len(all) == 0:
+		d.check(tts.Check{Label: "no Claude Code or Codex data directory", Fix: "Start a session with either CLI, or set CLAUDE_CONFIG_DIR / CODEX_HOME if you moved them"})
+	case found == 0:
+		// sessions from the other CLIs are enough
+		fmt.Println("[--] no Claude Code or Codex data directory")
+	}
+
+	fmt.Println("\nSessions")
+	skipped := make(map[model.Source]int)
+	for _, diag := range diags {
+		skipped[diag.Source]++
+	}
+	for _, p := range source.All() {
+		line := fmt.Sprintf("  %-12s %d", p.Label(), counts[p.Source()])
+		if n := skipped[p.Source()]; n > 0 {
+			line += fmt.Sprintf(", %d skipped", n)
+		}
+		fmt.Println(line)
+	}
+
+	if len(diags) > 0 {
+		// group by reason, keeping the order reasons first appear in
+		var reasons []model.Reason
+		byReason := make(map[model.Reason][]model.Diagnostic)
+		for _, diag := range diags {
+			if byReason[diag.Reason] == nil {
+				reasons = append(reasons, diag.Reason)
+			}
+			byReason[diag.Reason] = append(byReason[diag.Reason], diag)
+		}
+
+		fmt.Printf("\nSkipped %d files\n", len(diags))
+		failed := false
+		for _, r := range reasons {
+			// the CLIs routinely leave empty transcripts behind
+			if r == model.ReasonEmpty {
+				fmt.Printf("[--] %s (%d)\n", r.Describe(), len(byReason[r]))
+			} else {
+				fmt.Printf("[fail] %s (%d)\n", r.Describe(), len(byReason[r]))
+				d.problems++
+				failed = true
+			}
+			for _, diag := range byReason[r] {
+				line := fmt.Sprintf("  %-6s %s", diag.Source, diag.Path)
+				if diag.Detail != "" {
+					line += ": " + diag.Detail
+				}
+				fmt.Println(line)
+			}
+		}
+		if failed {
+			fmt.Println("  If these are real sessions, the CLI may have changed its format: please open an issue with this output")
+		}
+	}
+
+	fmt.Println("\nTTS")
+	if tts.Installed() {
+		for _, c := range tts.Doctor() {
+			d.check(c)
+		}
+	} else {
+		fmt.Println("[--] not set up (optional: vbs tts setup)")
+	}
+
+	fmt.Println()
+	switch d.problems {
+	case 0:
+		fmt.Println("No problems found.")
+	case 1:
+		fmt.Println("1 problem found.")
+		os.Exit(1)
+	default:
+		fmt.Printf("%d problems found.\n", d.problems)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,99 @@
+// Package fuzzy implements fzf-style fuzzy matching: the pattern's
+// characters must appear in order, and matches are scored so that word
+// starts and contiguous runs rank above scattered hits.
+package fuzzy
+
+import "unicode"
+
+// Scoring, loosely following fzf's v1 algorithm.
+const (
+	scoreMatch        = 16
+	bonusBoundary     = 8 // match at the start of a word
+	bonusCamel        = 7 // match at a lower-to-upper case change
+	bonusConsecutive  = 4 // match right after the previous one
+	bonusFirstCharMul = 2 // the first pattern character's bonus counts double
+	penaltyGapStart   = 3
+	penaltyGapExtend  = 1
+)
+
+// Match reports whether pattern matches text, ignoring case, with a score
+// (higher is better) and the rune positions of the matched characters.
+func Match(pattern, text string) (score int, positions []int, ok bool) {
+	pat := []rune(pattern)
+	if len(pat) == 0 {
+		return 0, nil, true
+	}
+	for i, r := range pat {
+		pat[i] = unicode.ToLower(r)
+	}
+	orig := []rune(text)
+	lower := make([]rune, len(orig))
+	for i, r := range orig {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	// forward: find where the earliest full match ends
+	pi, end := 0, -1
+	for i, r := range lower {
+		if r == pat[pi] {
+			pi++
+			if pi == len(pat) {
+				end = i
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+
+	// backward from there: the shortest window ending at end
+	positions = make([]int, len(pat))
+	pi = len(pat) - 1
+	for i := end; i >= 0 && pi >= 0; i-- {
+		if lower[i] == pat[pi] {
+			positions[pi] = i
+			pi--
+		}
+	}
+
+	return scorePositions(orig, positions), positions, true
+}
+
+func scorePositions(text []rune, positions []int) int {
+	score := 0
+	for k, i := range positions {
+		s := scoreMatch
+		bonus := charBonus(text, i)
+		if k == 0 {
+			bonus *= bonusFirstCharMul
+		}
+		s += bonus
+		if k > 0 {
+			if gap := i - positions[k-1] - 1; gap == 0 {
+				s += bonusConsecutive
+			} else {
+				s -= penaltyGapStart + (gap-1)*penaltyGapExtend
+			}
+		}
+		score += s
+	}
+	return score
+}
+
+// charBonus rewards a match at position i for being at a word start.
+func charBonus(text []rune, i int) int {
+	if i == 0 {
+		return bonusBoundary
+	}
+	prev, cur := text[i-1], text[i]
+	switch {
+	case !unicode.IsLetter(prev) && !unicode.IsDigit(prev):
+		return bonusBoundary
+	case unicode.IsLower(prev) && unicode.IsUpper(cur):
+		return bonusCamel
+	case !unicode.IsDigit(prev) && unicode.IsDigit(cur):
+		return bonusCamel
+	}
+	return 0
+}
@@ -2,75 +2,55 @@ package launcher
 
 import (
 	"fmt"
-	"os"
 
+	"github.com/jackwu/vibesession/config"
 	"github.com/jackwu/vibesession/model"
+	"github.com/jackwu/vibesession/source"
 )
 
 // BuildCommand returns the shell command to resume a session.
 func BuildCommand(s model.Session) string {
-	switch s.Source {
-	case model.SourceClaude:
-		return fmt.Sprintf("cd %s && claude -r %s", shellQuote(s.CWD), shellQuote(s.ID))
-	case model.SourceCodex:
-		return fmt.Sprintf("cd %s && codex resume %s", shellQuote(s.CWD), shellQuote(s.ID))
-	default:
+	p := source.Lookup(s.Source)
+	if p == nil {
 		return ""
 	}
+	return p.ResumeCommand(s)
 }
 
 // BuildYoloCommand returns the shell command to resume a session in yolo mode.
 func BuildYoloCommand(s model.Session) string {
-	switch s.Source {
-	case model.SourceClaude:
-		return fmt.Sprintf("cd %s && claude -r %s --dangerously-skip-permissions", shellQuote(s.CWD), shellQuote(s.ID))
-	case model.SourceCodex:
-		return fmt.Sprintf("cd %s && codex resume %s --full-auto", shellQuote(s.CWD), shellQuote(s.ID))
-	default:
+	p := source.Lookup(s.Source)
+	if p == nil {
 		return ""
 	}
+	return p.YoloCommand(s)
+}
+
+// ResumeNote returns what the resume command of s does beyond resuming it,
+// or "" if nothing.
+func ResumeNote(s model.Session) string {
+	if n, ok := source.Lookup(s.Source).(source.ResumeNoter); ok {
+		return n.ResumeNote(s)
+	}
+	return ""
 }
 
 // BuildNewCommand returns the shell command to start a new session.
-func BuildNewCommand(tool string, dir string, yolo bool) string {
-	dir = expandTilde(dir)
-	cd := fmt.Sprintf("cd %s", shellQuote(dir))
-	switch tool {
-	case "claude":
-		if yolo {
-			return cd + " && claude --dangerously-skip-permissions"
-		}
-		return cd + " && claude"
-	case "codex":
-		if yolo {
-			return cd + " && codex --full-auto"
-		}
-		return cd + " && codex"
-	default:
+func BuildNewCommand(src model.Source, dir string, yolo bool) string {
+	p := source.Lookup(src)
+	if p == nil {
 		return ""
 	}
+	return p.NewCommand(config.ExpandHome(dir), yolo)
 }
 
-func expandTilde(path string) string {
-	if len(path) == 0 {
-		return path
-	}
-	if path[0] == '~' {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return path
-		}
-		if len(path) == 1 {
-			return home
-		}
-		if path[1] == '/' {
-			return home + path[1:]
-		}
-	}
-	return path
+// Cd returns a shell command that changes into dir.
+func Cd(dir string) string {
+	return fmt.Sprintf("cd %s", ShellQuote(dir))
 }
 
-func shellQuote(s string) string {
+// ShellQuote quotes s for safe use as a single shell word.
+func ShellQuote(s string) string {
 	// simple quoting: wrap in single quotes, escape existing single quotes
 	return "'" + replaceAll(s, "'", "'\\''") + "'"
 }
@@ -6,10 +6,19 @@ import (
 	"os/exec"
 	"runtime"
 	"sort"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/jackwu/vibesession/model"
+	"github.com/jackwu/vibesession/pricing"
+	"github.com/jackwu/vibesession/query"
 	"github.com/jackwu/vibesession/scanner"
+	"github.com/jackwu/vibesession/search"
+	"github.com/jackwu/vibesession/source"
+	_ "github.com/jackwu/vibesession/source/aider"
+	_ "github.com/jackwu/vibesession/source/claude"
+	_ "github.com/jackwu/vibesession/source/codex"
+	_ "github.com/jackwu/vibesession/source/gemini"
 	"github.com/jackwu/vibesession/tts"
 	"github.com/jackwu/vibesession/tui"
 )
@@ -38,37 +47,63 @@ func main() {
 		return
 	}
 
-	// scan both sources concurrently
-	claudeCh := make(chan []model.Session)
-	codexCh := make(chan []model.Session)
+	// scan all registered sources concurrently
+	all, diags := source.ScanAll()
 
-	go func() { claudeCh <- scanner.ScanClaude() }()
-	go func() { codexCh <- scanner.ScanCodex() }()
+	// persist the session index so the next launch only re-parses changed files
+	scanner.SaveIndex()
 
-	claudeSessions := <-claudeCh
-	codexSessions := <-codexCh
-
-	var all []model.Session
-	all = append(all, claudeSessions...)
-	all = append(all, codexSessions...)
+	// subcommand: vbs doctor
+	if len(os.Args) > 1 && os.Args[1] == "doctor" {
+		runDoctor(all, diags)
+		return
+	}
 
 	if len(all) == 0 {
 		fmt.Println("No sessions found.")
 		os.Exit(0)
 	}
 
+	// subcommand: vbs search <query>
+	if len(os.Args) > 1 && os.Args[1] == "search" {
+		runSearch(all, strings.Join(os.Args[2:], " "))
+		return
+	}
+
 	// --list flag: print sessions as plain text (for testing / scripting)
+	// with a query (same syntax as the TUI search): vbs --list source:codex after:7d
 	if len(os.Args) > 1 && os.Args[1] == "--list" {
+		q, err := query.Parse(strings.Join(os.Args[2:], " "))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid query: %v\n", err)
+			os.Exit(2)
+		}
 		sort.Slice(all, func(i, j int) bool {
-			return all[i].Time.After(all[j].Time)
+			return all[i].Updated().After(all[j].Updated())
 		})
 		for _, s := range all {
-			summary := s.Summary
+			if !q.Match(s) {
+				continue
+			}
+			summary := s.Headline()
 			if s.TeamName != "" {
 				summary = "[team:" + s.TeamName + "] " + summary
 			}
-			fmt.Printf("%-6s │ %s │ %s │ %-14s │ %s\n",
-				s.Source, s.ShortID, s.Time.Format("01-02 15:04"), s.Project, summary)
+			if len(s.Children) > 0 {
+				summary = fmt.Sprintf("[agents:%d] ", len(s.Children)) + summary
+			}
+			if s.Compactions > 0 {
+				summary = fmt.Sprintf("[compacted:%d] ", s.Compactions) + summary
+			}
+			if s.Archived {
+				summary = "[archived] " + summary
+			}
+			cost := ""
+			if total := s.TotalUsage().Total(); total > 0 {
+				cost = pricing.FormatTokens(total) + " " + pricing.FormatCost(s.Usage)
+			}
+			fmt.Printf("%-6s │ %s │ %s │ %-14s │ %14s │ %s\n",
+				s.Source, s.ShortID, s.Updated().Format("01-02 15:04"), s.Project, cost, summary)
 		}
 		return
 	}
@@ -77,6 +112,7 @@ func main() {
 	if cwd, err := os.Getwd(); err == nil {
 		m.SetCWD(cwd)
 	}
+	m.SetDiagnostics(diags)
 	p := tea.NewProgram(m, tea.WithAltScreen())
 	result, err := p.Run()
 	if err != nil {
@@ -110,3 +146,32 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// runSearch prints the sessions whose conversation matches query, with the
+// matching part of the conversation.
+func runSearch(all []model.Session, query string) {
+	if strings.TrimSpace(query) == "" {
+		fmt.Fprintln(os.Stderr, "usage: vbs search <query>")
+		os.Exit(2)
+	}
+
+	ix := search.Open()
+	ix.Update(all)
+	if err := ix.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not save search index: %v\n", err)
+	}
+
+	results := ix.Find(all, query, 50)
+	if len(results) == 0 {
+		fmt.Println("No matches.")
+		os.Exit(1)
+	}
+	for _, r := range results {
+		s := r.Session
+		fmt.Printf("%-6s │ %s │ %s │ %-14s │ %s\n",
+			s.Source, s.ShortID, s.Updated().Format("01-02 15:04"), s.Project, s.Headline())
+		if r.Snippet != "" {
+			fmt.Printf("       %s\n", r.Snippet)
+		}
+	}
+}
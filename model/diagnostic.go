@@ -0,0 +1,38 @@
+package model
+
+// Diagnostic explains why a transcript file was skipped instead of listed.
+// Many of them at once usually mean an agent CLI changed its format.
+type Diagnostic struct {
+	Source Source
+	Path   string // the file that was skipped
+	Reason Reason
+	Detail string // specifics, such as the error or what was expected
+}
+
+// Reason classifies why a file was skipped.
+type Reason string
+
+const (
+	ReasonUnreadable Reason = "unreadable"  // the file or directory could not be read
+	ReasonEmpty      Reason = "empty"       // the file has no content
+	ReasonTooLong    Reason = "line-length" // a header line exceeds the read limit
+	ReasonNotJSON    Reason = "not-json"    // the content does not parse
+	ReasonNoSession  Reason = "no-session"  // parsed, but no session id or header was found
+)
+
+// Describe returns a short human-readable explanation of r.
+func (r Reason) Describe() string {
+	switch r {
+	case ReasonUnreadable:
+		return "could not be read"
+	case ReasonEmpty:
+		return "empty file"
+	case ReasonTooLong:
+		return "first lines too long to read"
+	case ReasonNotJSON:
+		return "not valid JSON"
+	case ReasonNoSession:
+		return "no session found (format change?)"
+	}
+	return string(r)
+}
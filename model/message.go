@@ -2,8 +2,57 @@ package model
 
 // Message represents a parsed conversation message from a session file.
 type Message struct {
-	Role      string   // "user" or "assistant"
-	Text      string   // rendered message content
-	ToolCalls []string // tool call summaries like "Read: main.go"
-	Index     int      // sequential index in conversation
+	Role      string     // "user", "assistant", or "system" for a compaction or a note from vbs
+	Text      string     // rendered message content
+	Thinking  string     // reasoning the model recorded before replying, if any
+	ToolCalls []ToolCall // tools invoked by this message, in order
+	Index     int        // sequential index in conversation
+	Fork      *Fork      // set on the first message of a branch, if the conversation forked here
+
+	Compaction *Compaction // set on the system message marking a compaction
+
+	Usage Usage // tokens consumed producing this message
+}
+
+// IsNote reports whether m is a note from vbs rather than part of the
+// conversation, e.g. about lines of the transcript it could not read.
+func (m Message) IsNote() bool {
+	return m.Role == "system" && m.Compaction == nil
+}
+
+// ToolCall is one tool invocation and, once it came back, its result.
+type ToolCall struct {
+	ID      string // tool_use / call id the result is paired by
+	Name    string // tool name as the agent CLI reports it
+	Summary string // one-line description like "Read: main.go"
+	Input   string // raw input, usually JSON
+
+	HasResult bool   // a result was recorded
+	Output    string // excerpt of the result
+	IsError   bool   // the tool failed or the command exited non-zero
+
+	Subagent string // transcript of the subagent a Task call spawned
+}
+
+// Fork marks where a conversation branched, e.g. because an earlier prompt
+// was edited or a reply regenerated.
+type Fork struct {
+	Branch int      // the branch this message starts, 0-based in the order written
+	Leaves []string // for each branch, the entry its latest continuation ends at
+}
+
+// Compaction records that the agent compacted its context here, replacing
+// the conversation so far with a summary.
+type Compaction struct {
+	Trigger   string // "auto" or "manual", if known
+	PreTokens int    // tokens in context before compacting, if known
+	Summary   string // the summary the conversation continued from
+}
+
+// Cursor is a point between two messages of a transcript that reading can
+// resume from, so long conversations can be read a page at a time.
+type Cursor struct {
+	Offset int64 // byte offset of the line the next message starts at
+	Index  int   // Index of the next message
+	Tokens Usage // tokens used so far, for CLIs that only log running totals
 }
@@ -7,6 +7,8 @@ type Source string
 const (
 	SourceClaude Source = "Claude"
 	SourceCodex  Source = "Codex"
+	SourceGemini Source = "Gemini"
+	SourceAider  Source = "Aider"
 )
 
 type Session struct {
@@ -16,7 +18,69 @@ type Session struct {
 	Time     time.Time
 	Project  string // last component of CWD
 	CWD      string // full working directory path
-	Summary  string // first user message, truncated
+	Summary  string // first meaningful user message, truncated
+	Title    string // the agent's own title for the session, if it recorded one
 	FilePath string // path to .jsonl file
+	Root     string // the data directory FilePath was found under, e.g. ~/.claude
 	TeamName string // non-empty if this is a team/subagent session
+	Archived bool   // the CLI archived the session (Codex archived_sessions)
+
+	AgentID  string    // non-empty for a Task subagent transcript
+	Children []Session // subagent transcripts spawned by this session, oldest first
+
+	Usage map[string]Usage // token usage per model name
+
+	Model      string // model used (most recent if it changed mid-session)
+	GitBranch  string // git branch of CWD when the session ran
+	CLIVersion string // version of the agent CLI that wrote the transcript
+
+	StartedAt    time.Time // first timestamp in the transcript, zero if unknown
+	EndedAt      time.Time // last timestamp in the transcript, zero if unknown
+	MessageCount int       // user prompts and assistant replies
+	Compactions  int       // times the agent compacted its context
+
+	Tools []string // distinct tools used ("Edit", "Shell", ...), in order of first use
+	Files []string // files named in tool inputs (read, edited, patched)
+}
+
+// Duration returns how long the session ran, or 0 if unknown.
+func (s Session) Duration() time.Duration {
+	if s.StartedAt.IsZero() || s.EndedAt.IsZero() || s.EndedAt.Before(s.StartedAt) {
+		return 0
+	}
+	return s.EndedAt.Sub(s.StartedAt)
+}
+
+// Started returns when the session started, falling back to Time.
+func (s Session) Started() time.Time {
+	if !s.StartedAt.IsZero() {
+		return s.StartedAt
+	}
+	return s.Time
+}
+
+// Updated returns when the session was last active, falling back to Time
+// (the file's mtime) for transcripts without timestamps.
+func (s Session) Updated() time.Time {
+	if !s.EndedAt.IsZero() {
+		return s.EndedAt
+	}
+	return s.Time
+}
+
+// Headline returns the session's title, or its first prompt if it has none.
+func (s Session) Headline() string {
+	if s.Title != "" {
+		return s.Title
+	}
+	return s.Summary
+}
+
+// TotalUsage returns the session's token usage summed over all models.
+func (s Session) TotalUsage() Usage {
+	var total Usage
+	for _, u := range s.Usage {
+		total = total.Add(u)
+	}
+	return total
 }
@@ -0,0 +1,35 @@
+package model
+
+// Usage counts tokens consumed by a message or session.
+// Input excludes tokens served from or written to the prompt cache.
+type Usage struct {
+	Input         int `json:"input,omitempty"`
+	Output        int `json:"output,omitempty"`
+	CacheRead     int `json:"cache_read,omitempty"`
+	CacheCreation int `json:"cache_creation,omitempty"`
+}
+
+// Add returns the sum of u and o.
+func (u Usage) Add(o Usage) Usage {
+	return Usage{
+		Input:         u.Input + o.Input,
+		Output:        u.Output + o.Output,
+		CacheRead:     u.CacheRead + o.CacheRead,
+		CacheCreation: u.CacheCreation + o.CacheCreation,
+	}
+}
+
+// Sub returns u minus o.
+func (u Usage) Sub(o Usage) Usage {
+	return Usage{
+		Input:         u.Input - o.Input,
+		Output:        u.Output - o.Output,
+		CacheRead:     u.CacheRead - o.CacheRead,
+		CacheCreation: u.CacheCreation - o.CacheCreation,
+	}
+}
+
+// Total returns the number of tokens across all categories.
+func (u Usage) Total() int {
+	return u.Input + u.Output + u.CacheRead + u.CacheCreation
+}
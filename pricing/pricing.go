@@ -0,0 +1,121 @@
+// Package pricing estimates what a session cost from its token usage.
+package pricing
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/jackwu/vibesession/config"
+	"github.com/jackwu/vibesession/model"
+)
+
+// defaultPrices are list prices in USD per million tokens, keyed by model
+// name prefix. Users can override or extend them via "prices" in config.json.
+var defaultPrices = map[string]config.Price{
+	"claude-opus-4-5":   {Input: 5, Output: 25, CacheRead: 0.5, CacheWrite: 6.25},
+	"claude-opus-4":     {Input: 15, Output: 75, CacheRead: 1.5, CacheWrite: 18.75},
+	"claude-sonnet-4":   {Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75},
+	"claude-3-7-sonnet": {Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75},
+	"claude-3-5-sonnet": {Input: 3, Output: 15, CacheRead: 0.3, CacheWrite: 3.75},
+	"claude-haiku-4-5":  {Input: 1, Output: 5, CacheRead: 0.1, CacheWrite: 1.25},
+	"claude-3-5-haiku":  {Input: 0.8, Output: 4, CacheRead: 0.08, CacheWrite: 1},
+	"gpt-5":             {Input: 1.25, Output: 10, CacheRead: 0.125},
+	"gpt-5-mini":        {Input: 0.25, Output: 2, CacheRead: 0.025},
+	"gpt-5-nano":        {Input: 0.05, Output: 0.4, CacheRead: 0.005},
+	"gpt-4.1":           {Input: 2, Output: 8, CacheRead: 0.5},
+	"o3":                {Input: 2, Output: 8, CacheRead: 0.5},
+	"o4-mini":           {Input: 1.1, Output: 4.4, CacheRead: 0.275},
+	"gemini-2.5-pro":    {Input: 1.25, Output: 10, CacheRead: 0.31},
+	"gemini-2.5-flash":  {Input: 0.3, Output: 2.5, CacheRead: 0.075},
+}
+
+var (
+	loadOnce sync.Once
+	prices   map[string]config.Price
+)
+
+func table() map[string]config.Price {
+	loadOnce.Do(func() {
+		prices = make(map[string]config.Price, len(defaultPrices))
+		for k, v := range defaultPrices {
+			prices[k] = v
+		}
+		cfg, _ := config.Load()
+		for k, v := range cfg.Prices {
+			prices[k] = v
+		}
+	})
+	return prices
+}
+
+// Lookup returns the price of the model whose name has the longest matching prefix.
+func Lookup(modelName string) (config.Price, bool) {
+	var best string
+	for prefix := range table() {
+		if strings.HasPrefix(modelName, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return config.Price{}, false
+	}
+	return prices[best], true
+}
+
+// Cost estimates the USD cost of per-model usage. ok is false if any model
+// with tokens has no known price; the cost of the priced models is still returned.
+func Cost(usage map[string]model.Usage) (cost float64, ok bool) {
+	ok = true
+	for name, u := range usage {
+		if u.Total() == 0 {
+			continue
+		}
+		p, found := Lookup(name)
+		if !found {
+			ok = false
+			continue
+		}
+		cost += (float64(u.Input)*p.Input +
+			float64(u.Output)*p.Output +
+			float64(u.CacheRead)*p.CacheRead +
+			float64(u.CacheCreation)*p.CacheWrite) / 1e6
+	}
+	return cost, ok
+}
+
+// FormatTokens renders a token count compactly, e.g. "950", "12.3k", "1.2M".
+func FormatTokens(n int) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1e6)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1e3)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
+
+// FormatCost renders the estimated cost of usage, or "" when there was none.
+// Costs that exclude unpriced models are marked with a trailing "+".
+func FormatCost(usage map[string]model.Usage) string {
+	total := 0
+	for _, u := range usage {
+		total += u.Total()
+	}
+	if total == 0 {
+		return ""
+	}
+	cost, ok := Cost(usage)
+	if cost == 0 && !ok {
+		return "?"
+	}
+	s := fmt.Sprintf("$%.2f", cost)
+	if cost > 0 && cost < 0.01 {
+		s = "<$0.01"
+	}
+	if !ok {
+		s += "+"
+	}
+	return s
+}
@@ -0,0 +1,387 @@
+// Package query parses the session filter syntax shared by the TUI search
+// and "vbs --list":
+//
+//	source:codex project:payments after:2026-09-01 before:7d
+//	tool:Edit file:main.go is:archived "exact phrase" -excluded
+//
+// Terms are ANDed. A leading "-" negates any term. Bare words and quoted
+// phrases match the summary shown (the title, or the first prompt, see
+// WithPrompt), project, session ID, team, branch and model; in fuzzy mode
+// (see WithFuzzy) bare words match fzf-style.
+package query
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jackwu/vibesession/fuzzy"
+	"github.com/jackwu/vibesession/model"
+	"github.com/jackwu/vibesession/source"
+)
+
+// Query is a parsed filter. The zero Query matches every session.
+type Query struct {
+	terms  []term
+	fuzzy  bool
+	prompt bool // match the first prompt instead of the headline
+}
+
+type term struct {
+	negate bool
+	phrase bool   // a "quoted phrase", always matched exactly
+	key    string // "" for text, otherwise one of the keys below
+	value  string // lowercased
+	time   time.Time
+}
+
+// matchFields are the session fields bare words and phrases are matched in:
+// only the summary that is shown, so a match is always visible.
+func (q Query) matchFields(s model.Session) []string {
+	summary := s.Headline()
+	if q.prompt {
+		summary = s.Summary
+	}
+	return []string{summary, s.Project, s.ID, s.TeamName, s.GitBranch, s.Model}
+}
+
+var keys = map[string]bool{
+	"source":  true,
+	"project": true,
+	"after":   true,
+	"before":  true,
+	"tool":    true,
+	"file":    true,
+	"is":      true,
+}
+
+// Parse parses q, interpreting relative dates against the current time.
+func Parse(q string) (Query, error) {
+	return ParseAt(q, time.Now())
+}
+
+// ParseAt parses q, interpreting relative dates such as "7d" against now.
+func ParseAt(q string, now time.Time) (Query, error) {
+	var query Query
+	tokens, err := split(q)
+	if err != nil {
+		return Query{}, err
+	}
+	for _, tok := range tokens {
+		t := term{negate: tok.negate, phrase: tok.quoted}
+		text := tok.text
+		if !tok.quoted {
+			if rest, ok := strings.CutPrefix(text, "-"); ok && rest != "" {
+				t.negate = true
+				text = rest
+			}
+			if k, v, ok := strings.Cut(text, ":"); ok && keys[strings.ToLower(k)] {
+				t.key = strings.ToLower(k)
+				text = v + tok.quotedValue
+				if text == "" {
+					return Query{}, fmt.Errorf("%s: needs a value", t.key)
+				}
+			} else {
+				text += tok.quotedValue
+			}
+		}
+		t.value = strings.ToLower(text)
+
+		switch t.key {
+		case "source":
+			if !knownSource(t.value) {
+				return Query{}, fmt.Errorf("unknown source %q", text)
+			}
+		case "is":
+			if t.value != "archived" {
+				return Query{}, fmt.Errorf("unknown is:%s (use is:archived)", text)
+			}
+		case "after", "before":
+			at, err := parseDate(text, now)
+			if err != nil {
+				return Query{}, fmt.Errorf("%s: %v", t.key, err)
+			}
+			t.time = at
+		}
+		query.terms = append(query.terms, t)
+	}
+	return query, nil
+}
+
+// WithFuzzy returns q with bare words matched fuzzily (fzf-style) instead
+// of as substrings. Phrases, keyed terms and excluded words stay exact.
+func (q Query) WithFuzzy(on bool) Query {
+	q.fuzzy = on
+	return q
+}
+
+// WithPrompt returns q matching the first prompt of each session instead
+// of its headline (model.Session.Headline), for when that is what's shown.
+func (q Query) WithPrompt(on bool) Query {
+	q.prompt = on
+	return q
+}
+
+// Positions returns the rune positions in text, one of the fields of s that
+// bare words are matched in, matched by the text terms of q, for
+// highlighting. A fuzzy word is only highlighted in the field it matched
+// best, since a short one is a subsequence of many unrelated strings.
+func (q Query) Positions(s model.Session, text string) map[int]bool {
+	var pos map[int]bool
+	add := func(i int) {
+		if pos == nil {
+			pos = make(map[int]bool)
+		}
+		pos[i] = true
+	}
+	lower := []rune(strings.ToLower(text))
+	for _, t := range q.terms {
+		if t.key != "" || t.negate {
+			continue
+		}
+		if t.fuzzyWord(q.fuzzy) {
+			if _, field, ok := q.bestFuzzy(t.value, s); !ok || field != text {
+				continue
+			}
+			if _, positions, ok := fuzzy.Match(t.value, text); ok {
+				for _, i := range positions {
+					add(i)
+				}
+			}
+			continue
+		}
+		word := []rune(t.value)
+		for i := 0; i+len(word) <= len(lower); i++ {
+			if string(lower[i:i+len(word)]) == t.value {
+				for j := range word {
+					add(i + j)
+				}
+			}
+		}
+	}
+	return pos
+}
+
+// Ranked reports whether q orders results by Score rather than by time.
+func (q Query) Ranked() bool {
+	for _, t := range q.terms {
+		if t.fuzzyWord(q.fuzzy) {
+			return true
+		}
+	}
+	return false
+}
+
+// Score rates how well s matches the fuzzy words of q; higher is better.
+func (q Query) Score(s model.Session) int {
+	total := 0
+	for _, t := range q.terms {
+		if t.fuzzyWord(q.fuzzy) {
+			best, _, _ := q.bestFuzzy(t.value, s)
+			total += best
+		}
+	}
+	return total
+}
+
+func (t term) fuzzyWord(fuzzyMode bool) bool {
+	return fuzzyMode && t.key == "" && !t.phrase && !t.negate
+}
+
+// bestFuzzy returns the score of word's best match among the fields of s,
+// and that field.
+func (q Query) bestFuzzy(word string, s model.Session) (int, string, bool) {
+	best, field, found := 0, "", false
+	for _, f := range q.matchFields(s) {
+		// hex IDs contain nearly any short subsequence; require a substring
+		if f == s.ID && !strings.Contains(strings.ToLower(f), word) {
+			continue
+		}
+		if score, _, ok := fuzzy.Match(word, f); ok && (!found || score > best) {
+			best, field, found = score, f, true
+		}
+	}
+	return best, field, found
+}
+
+// Empty reports whether q has no terms.
+func (q Query) Empty() bool {
+	return len(q.terms) == 0
+}
+
+// Match reports whether s satisfies every term of q.
+func (q Query) Match(s model.Session) bool {
+	for _, t := range q.terms {
+		if t.fuzzyWord(q.fuzzy) {
+			if _, _, ok := q.bestFuzzy(t.value, s); !ok {
+				return false
+			}
+			continue
+		}
+		if t.match(s, q) == t.negate {
+			return false
+		}
+	}
+	return true
+}
+
+func (t term) match(s model.Session, q Query) bool {
+	switch t.key {
+	case "source":
+		return strings.ToLower(string(s.Source)) == t.value
+	case "project":
+		return strings.Contains(strings.ToLower(s.Project), t.value) ||
+			strings.Contains(strings.ToLower(s.CWD), t.value)
+	case "after":
+		// still active on or after the date
+		return !s.Updated().Before(t.time)
+	case "before":
+		// started before the date
+		return s.Started().Before(t.time)
+	case "tool":
+		for _, name := range s.Tools {
+			if strings.ToLower(name) == t.value {
+				return true
+			}
+		}
+		return false
+	case "file":
+		for _, f := range s.Files {
+			if fileMatches(strings.ToLower(f), t.value) {
+				return true
+			}
+		}
+		return false
+	case "is":
+		return s.Archived // the only state ParseAt accepts
+	}
+	return strings.Contains(q.Haystack(s), t.value)
+}
+
+// Haystack is the lowercased text that bare words and phrases are matched in.
+func (q Query) Haystack(s model.Session) string {
+	return strings.ToLower(strings.Join(q.matchFields(s), " "))
+}
+
+// fileMatches matches a path by base name ("main.go"), by trailing path
+// components ("cmd/main.go") or, for values with a wildcard, by glob.
+func fileMatches(file, value string) bool {
+	if strings.ContainsAny(value, "*?[") {
+		ok, _ := path.Match(value, path.Base(file))
+		if !ok {
+			ok, _ = path.Match(value, file)
+		}
+		return ok
+	}
+	return file == value || strings.HasSuffix(file, "/"+value)
+}
+
+func knownSource(name string) bool {
+	for _, p := range source.All() {
+		if strings.ToLower(string(p.Source())) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDate accepts YYYY-MM-DD (local midnight) or an age relative to now
+// such as 36h, 7d or 2w.
+func parseDate(s string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", s, now.Location()); err == nil {
+		return t, nil
+	}
+	if len(s) >= 2 {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err == nil && n >= 0 {
+			switch s[len(s)-1] {
+			case 'h':
+				return now.Add(-time.Duration(n) * time.Hour), nil
+			case 'd':
+				return now.AddDate(0, 0, -n), nil
+			case 'w':
+				return now.AddDate(0, 0, -7*n), nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q (use YYYY-MM-DD or an age like 7d)", s)
+}
+
+type token struct {
+	text        string
+	quoted      bool   // the whole token was a "phrase"
+	negate      bool   // a quoted phrase preceded by "-"
+	quotedValue string // the value of key:"quoted value"
+}
+
+// split breaks q into whitespace-separated tokens, keeping "quoted phrases"
+// (also as key:"values") together.
+func split(q string) ([]token, error) {
+	var tokens []token
+	runes := []rune(q)
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+		if runes[i] == '"' {
+			end, err := closingQuote(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			if phrase := string(runes[i+1 : end]); phrase != "" {
+				tokens = append(tokens, token{text: phrase, quoted: true})
+			}
+			i = end + 1
+			continue
+		}
+
+		start := i
+		for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '"' {
+			i++
+		}
+		tok := token{text: string(runes[start:i])}
+		if i < len(runes) && runes[i] == '"' {
+			if tok.text == "-" {
+				// -"negated phrase"
+				end, err := closingQuote(runes, i)
+				if err != nil {
+					return nil, err
+				}
+				if phrase := string(runes[i+1 : end]); phrase != "" {
+					tokens = append(tokens, token{text: phrase, quoted: true, negate: true})
+				}
+				i = end + 1
+				continue
+			}
+			if !strings.HasSuffix(tok.text, ":") {
+				return nil, fmt.Errorf("unexpected quote after %q", tok.text)
+			}
+			end, err := closingQuote(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tok.quotedValue = string(runes[i+1 : end])
+			i = end + 1
+		}
+		if tok.text == "-" {
+			// the start of an exclusion still being typed; excluding
+			// nothing would hide every session
+			continue
+		}
+		tokens = append(tokens, tok)
+	}
+	return tokens, nil
+}
+
+func closingQuote(runes []rune, open int) (int, error) {
+	for j := open + 1; j < len(runes); j++ {
+		if runes[j] == '"' {
+			return j, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated quote")
+}
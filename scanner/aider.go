@@ -0,0 +1,437 @@
+package scanner
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackwu/vibesession/model"
+)
+
+// Aider keeps its history inside each project rather than in a central
+// directory. Every run appends to .aider.chat.history.md, starting with a
+// "# aider chat started at" header, so one file holds many sessions:
+//
+//	# aider chat started at 2026-09-01 10:00:00
+//	> Aider v0.60.0           (tool output and status lines)
+//	#### add a hello function (user prompt)
+//	Sure, here's ...          (assistant reply)
+//
+// Prompts also go to .aider.input.history, each as a "# <timestamp>" line
+// followed by its lines prefixed with "+". That file has no chat boundaries,
+// so a project without a chat history (deleted, or moved elsewhere with
+// --chat-history-file) is listed as a single session of prompts only.
+const (
+	aiderHistoryFile  = ".aider.chat.history.md"
+	aiderInputFile    = ".aider.input.history"
+	aiderHeaderPrefix = "# aider chat started at "
+	aiderTimeLayout   = "2006-01-02 15:04:05"
+	aiderInputLayout  = "2006-01-02 15:04:05.999999"
+)
+
+// aiderTokensRe matches the parts of a "> Tokens: 4.2k sent, 2.3k cache hit,
+// 150 received. Cost: ..." report line.
+var aiderTokensRe = regexp.MustCompile(`([\d.,]+)(k|M)? (sent|received|cache write|cache hit)`)
+
+// aiderChat is one "# aider chat started at" section of a history file.
+type aiderChat struct {
+	header  string // the timestamp text after the header prefix
+	started time.Time
+	lines   []string
+}
+
+// ScanAider looks for Aider chat histories in each of dirs, and returns
+// why any history files held no chats.
+func ScanAider(dirs []string) ([]model.Session, []model.Diagnostic) {
+	var sessions []model.Session
+	var diags []model.Diagnostic
+	seen := make(map[string]bool)
+
+	for _, dir := range dirs {
+		filePath := filepath.Join(dir, aiderHistoryFile)
+		if seen[filePath] {
+			continue
+		}
+		seen[filePath] = true
+
+		info, err := os.Stat(filePath)
+		if err != nil || info.IsDir() {
+			inputPath := filepath.Join(dir, aiderInputFile)
+			if info, err := os.Stat(inputPath); err == nil && !info.IsDir() {
+				s, diag := parseAiderInput(inputPath, info)
+				if diag != nil {
+					diags = append(diags, *diag)
+				} else {
+					sessions = append(sessions, *s)
+				}
+			}
+			continue
+		}
+		found, diag := parseAiderSessions(filePath, info)
+		if diag != nil {
+			diags = append(diags, *diag)
+		}
+		sessions = append(sessions, found...)
+	}
+
+	return sessions, diags
+}
+
+func parseAiderSessions(filePath string, info os.FileInfo) ([]model.Session, *model.Diagnostic) {
+	chats, err := readAiderChats(filePath)
+	if len(chats) == 0 {
+		switch {
+		case err == bufio.ErrTooLong:
+			return nil, skipFile(model.SourceAider, filePath, model.ReasonTooLong, "a line is over "+formatSize(maxLineSize))
+		case err != nil:
+			return nil, skipFile(model.SourceAider, filePath, model.ReasonUnreadable, err.Error())
+		case info.Size() == 0:
+			return nil, skipFile(model.SourceAider, filePath, model.ReasonEmpty, "")
+		}
+		return nil, skipFile(model.SourceAider, filePath, model.ReasonNoSession, "no \""+strings.TrimSpace(aiderHeaderPrefix)+"\" header")
+	}
+	cwd := filepath.Dir(filePath)
+
+	var sessions []model.Session
+	for i, c := range chats {
+		summary := ""
+		for _, line := range c.lines {
+			if text, ok := strings.CutPrefix(line, "#### "); ok && !strings.HasPrefix(text, "/") {
+				summary = text
+				break
+			}
+		}
+		summary = truncate(summary, 120)
+		if summary == "" {
+			summary = "(no message)"
+		}
+
+		var stats sessionStats
+		version := ""
+		for _, line := range c.lines {
+			if name, ok := aiderModel(line); ok {
+				stats.model = name
+			} else if u, ok := aiderTokens(line); ok {
+				stats.addUsage(stats.model, u)
+			} else if v, ok := strings.CutPrefix(line, "> Aider v"); ok && version == "" {
+				version = v
+			} else if file, ok := strings.CutPrefix(line, "> Applied edit to "); ok {
+				stats.addTool("Edit")
+				stats.addFile(file)
+			} else if strings.HasPrefix(line, "> Commit ") {
+				stats.addTool("Commit")
+			}
+		}
+
+		// earlier chats ended when the next one started; only the last
+		// one can have been written to as late as the file's mtime
+		t := info.ModTime()
+		if i < len(chats)-1 {
+			t = chats[i+1].started
+		}
+
+		id := aiderChatID(filePath, i, c.header)
+		sessions = append(sessions, model.Session{
+			ID:       id,
+			ShortID:  shortID(id),
+			Source:   model.SourceAider,
+			Time:     t,
+			Project:  filepath.Base(cwd),
+			CWD:      cwd,
+			Summary:  summary,
+			FilePath: filePath,
+			Usage:    stats.usage,
+
+			Model:      stats.model,
+			CLIVersion: version,
+
+			// Aider only logs when a chat started
+			StartedAt:    c.started,
+			MessageCount: len(aiderChatMessages(&c)),
+
+			Tools: stats.tools,
+			Files: stats.files,
+		})
+	}
+	return sessions, nil
+}
+
+// aiderChatID derives a stable ID for the n-th chat of a file from the file,
+// n and its start header, since Aider itself doesn't assign one. The header
+// alone repeats when two chats start in the same second; n stays put since
+// Aider only appends.
+func aiderChatID(filePath string, n int, header string) string {
+	sum := sha1.Sum([]byte(filePath + "\x00" + strconv.Itoa(n) + "\x00" + header))
+	return hex.EncodeToString(sum[:8])
+}
+
+// readAiderChats splits a history file into its chats. If reading fails
+// part way, the chats read so far are returned with the error.
+func readAiderChats(filePath string) ([]aiderChat, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 256*1024), maxLineSize)
+
+	var chats []aiderChat
+	for sc.Scan() {
+		line := sc.Text()
+		if header, ok := strings.CutPrefix(line, aiderHeaderPrefix); ok {
+			header = strings.TrimSpace(header)
+			started, _ := time.ParseInLocation(aiderTimeLayout, header, time.Local)
+			chats = append(chats, aiderChat{header: header, started: started})
+			continue
+		}
+		if len(chats) == 0 {
+			continue
+		}
+		c := &chats[len(chats)-1]
+		c.lines = append(c.lines, line)
+	}
+	return chats, sc.Err()
+}
+
+// aiderPrompt is one entry of an input history file.
+type aiderPrompt struct {
+	time time.Time
+	text string
+}
+
+// parseAiderInput lists an input history file as one session of prompts.
+func parseAiderInput(filePath string, info os.FileInfo) (*model.Session, *model.Diagnostic) {
+	prompts, err := readAiderInput(filePath)
+	if len(prompts) == 0 {
+		switch {
+		case err == bufio.ErrTooLong:
+			return nil, skipFile(model.SourceAider, filePath, model.ReasonTooLong, "a line is over "+formatSize(maxLineSize))
+		case err != nil:
+			return nil, skipFile(model.SourceAider, filePath, model.ReasonUnreadable, err.Error())
+		}
+		return nil, skipFile(model.SourceAider, filePath, model.ReasonEmpty, "")
+	}
+
+	summary := ""
+	for _, p := range prompts {
+		if !strings.HasPrefix(p.text, "/") {
+			summary = p.text
+			break
+		}
+	}
+	summary = truncate(summary, 120)
+	if summary == "" {
+		summary = "(no message)"
+	}
+
+	cwd := filepath.Dir(filePath)
+	id := aiderChatID(filePath, 0, "")
+	return &model.Session{
+		ID:       id,
+		ShortID:  shortID(id),
+		Source:   model.SourceAider,
+		Time:     info.ModTime(),
+		Project:  filepath.Base(cwd),
+		CWD:      cwd,
+		Summary:  summary,
+		FilePath: filePath,
+
+		StartedAt:    prompts[0].time,
+		MessageCount: len(prompts),
+	}, nil
+}
+
+// readAiderInput reads the prompts of an input history file. If reading
+// fails part way, the prompts read so far are returned with the error.
+func readAiderInput(filePath string) ([]aiderPrompt, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 256*1024), maxLineSize)
+
+	var prompts []aiderPrompt
+	var lines []string
+	var t time.Time
+	flush := func() {
+		if text := strings.TrimSpace(strings.Join(lines, "\n")); text != "" {
+			prompts = append(prompts, aiderPrompt{time: t, text: text})
+		}
+		lines = nil
+	}
+	for sc.Scan() {
+		line := sc.Text()
+		if stamp, ok := strings.CutPrefix(line, "# "); ok {
+			flush()
+			t, _ = time.ParseInLocation(aiderInputLayout, strings.TrimSpace(stamp), time.Local)
+		} else if text, ok := strings.CutPrefix(line, "+"); ok {
+			lines = append(lines, text)
+		}
+	}
+	flush()
+	return prompts, sc.Err()
+}
+
+// ParseAiderMessages returns the messages of the chat identified by sessionID,
+// or the prompts of an input history file.
+func ParseAiderMessages(filePath, sessionID string) []model.Message {
+	if filepath.Base(filePath) == aiderInputFile {
+		prompts, _ := readAiderInput(filePath)
+		var messages []model.Message
+		idx := 0
+		for _, p := range prompts {
+			messages = appendMessage(messages, &idx, "user", p.text, nil, model.Usage{})
+		}
+		return messages
+	}
+
+	var chat *aiderChat
+	chats, _ := readAiderChats(filePath)
+	for i := range chats {
+		if aiderChatID(filePath, i, chats[i].header) == sessionID {
+			chat = &chats[i]
+			break
+		}
+	}
+	if chat == nil {
+		return nil
+	}
+	return aiderChatMessages(chat)
+}
+
+func aiderChatMessages(chat *aiderChat) []model.Message {
+	var messages []model.Message
+	idx := 0
+	var role string
+	var buf []string
+	var tools []model.ToolCall
+	var usage model.Usage
+
+	flush := func() {
+		text := strings.TrimSpace(strings.Join(buf, "\n"))
+		buf = nil
+		if text == "" && len(tools) == 0 && usage.Total() == 0 {
+			return
+		}
+		messages = appendMessage(messages, &idx, role, text, tools, usage)
+		tools = nil
+		usage = model.Usage{}
+	}
+
+	for _, line := range chat.lines {
+		switch {
+		case strings.HasPrefix(line, "#### "):
+			if role != "user" {
+				flush()
+				role = "user"
+			}
+			buf = append(buf, strings.TrimPrefix(line, "#### "))
+
+		case strings.HasPrefix(line, "> ") || line == ">":
+			// tool output: keep edits and commits, drop status noise
+			out := strings.TrimSpace(strings.TrimPrefix(line, ">"))
+			if u, ok := aiderTokens(line); ok {
+				// reported after the reply the tokens were spent on
+				if role == "assistant" {
+					usage = usage.Add(u)
+				} else if len(messages) > 0 && messages[len(messages)-1].Role == "assistant" {
+					last := &messages[len(messages)-1]
+					last.Usage = last.Usage.Add(u)
+				}
+			} else if file, ok := strings.CutPrefix(out, "Applied edit to "); ok {
+				if role != "assistant" {
+					flush()
+					role = "assistant"
+				}
+				tools = append(tools, model.ToolCall{Name: "Edit", Summary: "Edit: " + shortPath(file)})
+			} else if commit, ok := strings.CutPrefix(out, "Commit "); ok {
+				if role != "assistant" {
+					flush()
+					role = "assistant"
+				}
+				tools = append(tools, model.ToolCall{Name: "Commit", Summary: "Commit: " + truncateStr(commit, 60)})
+			}
+
+		default:
+			if role != "assistant" {
+				if strings.TrimSpace(line) == "" {
+					// blank line between prompt and reply
+					if role == "user" {
+						buf = append(buf, line)
+					}
+					continue
+				}
+				flush()
+				role = "assistant"
+			}
+			buf = append(buf, line)
+		}
+	}
+	flush()
+
+	return messages
+}
+
+// aiderModel extracts the model name from a "> Main model: X with ..." line
+// ("> Model: X" in older versions).
+func aiderModel(line string) (string, bool) {
+	rest, ok := strings.CutPrefix(line, "> Main model: ")
+	if !ok {
+		rest, ok = strings.CutPrefix(line, "> Model: ")
+	}
+	if !ok {
+		return "", false
+	}
+	name, _, _ := strings.Cut(rest, " ")
+	// drop litellm provider prefixes such as "anthropic/"
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name, name != ""
+}
+
+// aiderTokens parses a "> Tokens: ..." report line. Aider's "sent" count
+// includes the cached prompt tokens.
+func aiderTokens(line string) (model.Usage, bool) {
+	rest, ok := strings.CutPrefix(line, "> Tokens: ")
+	if !ok {
+		return model.Usage{}, false
+	}
+	var u model.Usage
+	sent := 0
+	for _, m := range aiderTokensRe.FindAllStringSubmatch(rest, -1) {
+		n, err := strconv.ParseFloat(strings.ReplaceAll(m[1], ",", ""), 64)
+		if err != nil {
+			continue
+		}
+		switch m[2] {
+		case "k":
+			n *= 1e3
+		case "M":
+			n *= 1e6
+		}
+		switch m[3] {
+		case "sent":
+			sent = int(n)
+		case "received":
+			u.Output = int(n)
+		case "cache write":
+			u.CacheCreation = int(n)
+		case "cache hit":
+			u.CacheRead = int(n)
+		}
+	}
+	u.Input = max(0, sent-u.CacheRead-u.CacheCreation)
+	return u, true
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/jackwu/vibesession/model"
@@ -14,22 +15,63 @@ import (
 var teammateTagRe = regexp.MustCompile(`<teammate-message[^>]*>`)
 var teammateCloseRe = regexp.MustCompile(`</teammate-message>`)
 
-func ScanClaude() []model.Session {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil
+// ScanClaude lists the Claude Code sessions in each of roots, directories
+// laid out like ~/.claude, and why any transcript files were skipped. A
+// transcript found at the same place in more than one root, such as in a
+// synced copy, is listed from the first.
+func ScanClaude(roots []string) ([]model.Session, []model.Diagnostic) {
+	var sessions []model.Session
+	var diags []model.Diagnostic
+	var seen rootPaths
+	for _, root := range roots {
+		found, d := scanClaudeRoot(root)
+		sessions = append(sessions, seen.add(root, found)...)
+		diags = append(diags, d...)
+	}
+	return sessions, diags
+}
+
+// rootPaths records the transcripts of the roots scanned so far by their
+// path within the root.
+type rootPaths map[string]bool
+
+// add returns the sessions of root whose transcripts no earlier root had.
+// Sessions within one root are never dropped: transcripts there can share
+// a session ID, e.g. a session and the older agent-*.jsonl subagents.
+func (p *rootPaths) add(root string, found []model.Session) []model.Session {
+	if *p == nil {
+		*p = make(rootPaths)
+	}
+	var fresh []model.Session
+	var rels []string
+	for _, s := range found {
+		rel, err := filepath.Rel(root, s.FilePath)
+		if err != nil {
+			rel = s.FilePath
+		}
+		rels = append(rels, rel)
+		if !(*p)[rel] {
+			fresh = append(fresh, s)
+		}
+	}
+	for _, rel := range rels {
+		(*p)[rel] = true
 	}
+	return fresh
+}
 
-	projectsDir := filepath.Join(homeDir, ".claude", "projects")
+func scanClaudeRoot(root string) ([]model.Session, []model.Diagnostic) {
+	projectsDir := filepath.Join(root, "projects")
 	if _, err := os.Stat(projectsDir); os.IsNotExist(err) {
-		return nil
+		return nil, nil
 	}
 
 	var sessions []model.Session
+	var diags []model.Diagnostic
 
 	projectEntries, err := os.ReadDir(projectsDir)
 	if err != nil {
-		return nil
+		return nil, []model.Diagnostic{*skipFile(model.SourceClaude, projectsDir, model.ReasonUnreadable, err.Error())}
 	}
 
 	for _, projEntry := range projectEntries {
@@ -39,42 +81,97 @@ func ScanClaude() []model.Session {
 		projPath := filepath.Join(projectsDir, projEntry.Name())
 		fileEntries, err := os.ReadDir(projPath)
 		if err != nil {
+			diags = append(diags, *skipFile(model.SourceClaude, projPath, model.ReasonUnreadable, err.Error()))
 			continue
 		}
 
+		var projSessions []model.Session
+		children := make(map[string][]model.Session) // parent session ID -> subagents
+
 		for _, fe := range fileEntries {
 			name := fe.Name()
-			// only top-level .jsonl files, skip directories (subagents)
-			if fe.IsDir() || !strings.HasSuffix(name, ".jsonl") {
+
+			// <sessionId>/subagents/agent-*.jsonl holds the Task subagents of that session
+			if fe.IsDir() {
+				subagents, subDiags := scanClaudeSubagents(filepath.Join(projPath, name, "subagents"))
+				children[name] = append(children[name], subagents...)
+				diags = append(diags, subDiags...)
+				continue
+			}
+			if !strings.HasSuffix(name, ".jsonl") {
 				continue
 			}
 
+			info, err := fe.Info()
+			if err != nil {
+				continue
+			}
 			filePath := filepath.Join(projPath, name)
-			s := parseClaudeSession(filePath)
-			if s != nil {
-				sessions = append(sessions, *s)
+			s, diag := cachedParse(filePath, info, func() (*model.Session, *model.Diagnostic) {
+				return parseClaudeSession(filePath)
+			})
+			if s == nil {
+				if diag != nil {
+					diags = append(diags, *diag)
+				}
+				continue
+			}
+			// older versions wrote subagents next to their parent as
+			// agent-<id>.jsonl, sharing the parent's sessionId
+			if strings.HasPrefix(name, "agent-") {
+				s.AgentID = agentIDFromFile(name)
+				children[s.ID] = append(children[s.ID], *s)
+				continue
 			}
+			projSessions = append(projSessions, *s)
 		}
+
+		for i := range projSessions {
+			id := projSessions[i].ID
+			projSessions[i].Children = children[id]
+			delete(children, id)
+		}
+		// keep subagents whose parent transcript is gone as sessions of their own
+		for _, orphans := range children {
+			projSessions = append(projSessions, orphans...)
+		}
+		for i := range projSessions {
+			projSessions[i].Root = root
+			for j := range projSessions[i].Children {
+				projSessions[i].Children[j].Root = root
+			}
+		}
+		sessions = append(sessions, projSessions...)
 	}
 
-	return sessions
+	return sessions, diags
+}
+
+// claudeMetadataTypes are the transcript line types that carry no session.
+// Claude Code leaves files made only of these, e.g. the titles of other
+// sessions, which are no sign of a format change.
+var claudeMetadataTypes = map[string]bool{
+	"summary":               true,
+	"file-history-snapshot": true,
 }
 
-func parseClaudeSession(filePath string) *model.Session {
+// parseClaudeSession reads the session in a transcript, returns why there
+// is none, or neither for a file that holds only metadata.
+func parseClaudeSession(filePath string) (*model.Session, *model.Diagnostic) {
 	f, err := os.Open(filePath)
 	if err != nil {
-		return nil
+		return nil, skipFile(model.SourceClaude, filePath, model.ReasonUnreadable, err.Error())
 	}
 	defer f.Close()
 
 	info, err := f.Stat()
 	if err != nil {
-		return nil
+		return nil, skipFile(model.SourceClaude, filePath, model.ReasonUnreadable, err.Error())
 	}
 
 	scanner := bufio.NewScanner(f)
 	// increase buffer for potentially large first lines
-	scanner.Buffer(make([]byte, 0, 256*1024), 256*1024)
+	scanner.Buffer(make([]byte, 0, headerLineSize), headerLineSize)
 
 	// scan lines to find the first one with a sessionId
 	// (some files start with file-history-snapshot or other non-session lines)
@@ -83,6 +180,8 @@ func parseClaudeSession(filePath string) *model.Session {
 		CWD       string `json:"cwd"`
 		Type      string `json:"type"`
 		TeamName  string `json:"teamName"`
+		GitBranch string `json:"gitBranch"`
+		Version   string `json:"version"`
 		Message   struct {
 			Role    string `json:"role"`
 			Content string `json:"content"`
@@ -90,35 +189,31 @@ func parseClaudeSession(filePath string) *model.Session {
 	}
 
 	found := false
-	for i := 0; i < 10 && scanner.Scan(); i++ {
+	read, parsed := 0, 0
+	metadataOnly := true
+	for ; read < 10 && scanner.Scan(); read++ {
+		firstLine.Type = ""
 		if err := json.Unmarshal(scanner.Bytes(), &firstLine); err != nil {
 			continue
 		}
+		parsed++
 		if firstLine.SessionID != "" {
 			found = true
 			break
 		}
+		if !claudeMetadataTypes[firstLine.Type] {
+			metadataOnly = false
+		}
 	}
 	if !found {
-		return nil
-	}
-
-	summary := firstLine.Message.Content
-	// strip teammate message tags
-	summary = teammateTagRe.ReplaceAllString(summary, "")
-	summary = teammateCloseRe.ReplaceAllString(summary, "")
-	summary = strings.TrimSpace(summary)
-
-	// for teammate sessions, extract the task description instead of boilerplate
-	if strings.HasPrefix(summary, "You are") {
-		// try to find the actual task content after "Your task is"
-		if idx := strings.Index(summary, "Your task is"); idx >= 0 {
-			summary = summary[idx:]
+		if scanner.Err() == nil && read > 0 && parsed == read && metadataOnly {
+			// titles or file snapshots for other sessions: nothing to list
+			return nil, nil
 		}
+		return nil, headerSkipped(model.SourceClaude, filePath, scanner.Err(), read, parsed, "no sessionId in the first 10 lines")
 	}
-	// strip XML-like system tags
-	summary = regexp.MustCompile(`<[^>]+>`).ReplaceAllString(summary, "")
-	summary = strings.TrimSpace(summary)
+
+	summary := cleanClaudePrompt(firstLine.Message.Content)
 
 	// if first line is not a user message, scan ahead
 	if firstLine.Type != "user" || summary == "" {
@@ -134,24 +229,27 @@ func parseClaudeSession(filePath string) *model.Session {
 				continue
 			}
 			if line.Type == "user" && line.Message.Content != "" {
-				summary = line.Message.Content
-				summary = teammateTagRe.ReplaceAllString(summary, "")
-				summary = teammateCloseRe.ReplaceAllString(summary, "")
-				summary = strings.TrimSpace(summary)
+				summary = cleanClaudePrompt(line.Message.Content)
 				break
 			}
 		}
 	}
 
-	// truncate summary
-	summary = truncate(summary, 120)
-
 	// derive project name from CWD
 	project := filepath.Base(firstLine.CWD)
 	if project == "" || project == "." {
 		project = "unknown"
 	}
 
+	stats := scanClaudeStats(filePath)
+	// the first prompt may be a nudge like "continue" or a resumed
+	// session's boilerplate; prefer the first one saying what it's about
+	if stats.prompt != "" {
+		summary = stats.prompt
+	}
+	summary = truncate(summary, 120)
+	start, end := transcriptTimeSpan(filePath)
+
 	return &model.Session{
 		ID:       firstLine.SessionID,
 		ShortID:  shortID(firstLine.SessionID),
@@ -160,9 +258,66 @@ func parseClaudeSession(filePath string) *model.Session {
 		Project:  project,
 		CWD:      firstLine.CWD,
 		Summary:  summary,
+		Title:    truncate(stats.title, 120),
 		FilePath: filePath,
 		TeamName: firstLine.TeamName,
+		Usage:    stats.usage,
+
+		Model:      stats.model,
+		GitBranch:  firstLine.GitBranch,
+		CLIVersion: firstLine.Version,
+
+		StartedAt:    start,
+		EndedAt:      end,
+		MessageCount: stats.messages,
+		Compactions:  stats.compactions,
+
+		Tools: stats.tools,
+		Files: stats.files,
+	}, nil
+}
+
+// scanClaudeSubagents parses the subagent transcripts in dir, oldest first.
+func scanClaudeSubagents(dir string) ([]model.Session, []model.Diagnostic) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, []model.Diagnostic{*skipFile(model.SourceClaude, dir, model.ReasonUnreadable, err.Error())}
+	}
+	var subagents []model.Session
+	var diags []model.Diagnostic
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".jsonl") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		filePath := filepath.Join(dir, e.Name())
+		s, diag := cachedParse(filePath, info, func() (*model.Session, *model.Diagnostic) {
+			return parseClaudeSession(filePath)
+		})
+		if s == nil {
+			if diag != nil {
+				diags = append(diags, *diag)
+			}
+			continue
+		}
+		s.AgentID = agentIDFromFile(e.Name())
+		subagents = append(subagents, *s)
 	}
+	sort.Slice(subagents, func(i, j int) bool {
+		return subagents[i].Time.Before(subagents[j].Time)
+	})
+	return subagents, diags
+}
+
+// agentIDFromFile extracts <id> from an agent-<id>.jsonl file name.
+func agentIDFromFile(name string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(name, "agent-"), ".jsonl")
 }
 
 func shortID(id string) string {
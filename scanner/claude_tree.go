@@ -0,0 +1,216 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jackwu/vibesession/model"
+)
+
+// claudeTree is the uuid/parentUuid structure of a Claude transcript.
+// Rewinding or editing an earlier prompt starts a new branch from that
+// point, so a transcript holds every branch, in the order written.
+type claudeTree struct {
+	parent   map[string]string
+	children map[string][]string // in file order
+	// latest is the most recent user or assistant entry in the subtree
+	// rooted at each entry; empty if the subtree has none.
+	latest map[string]string
+	// last is the most recent user or assistant entry of the main chain,
+	// where the active branch ends.
+	last string
+}
+
+// claudeOutline is what reading a Claude transcript in order needs to know
+// about the rest of it: how the conversation branches and which subagent
+// each Task call spawned.
+type claudeOutline struct {
+	tree      *claudeTree       // nil for transcripts without uuids
+	subagents map[string]string // subagent transcript by tool_use id
+}
+
+// outlineCache keeps the outline of the transcript read last, which is
+// read again page after page.
+var outlineCache struct {
+	sync.Mutex
+	path    string
+	size    int64
+	modTime time.Time
+	outline *claudeOutline
+}
+
+// readClaudeOutline reads the outline of a transcript, matching its Task
+// calls against subagents.
+func readClaudeOutline(filePath string, subagents []model.Session) *claudeOutline {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return &claudeOutline{}
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return &claudeOutline{}
+	}
+
+	outlineCache.Lock()
+	if outlineCache.path == filePath && outlineCache.size == info.Size() && outlineCache.modTime.Equal(info.ModTime()) {
+		defer outlineCache.Unlock()
+		return outlineCache.outline
+	}
+	outlineCache.Unlock()
+
+	var entries []claudeEntry
+	var tasks []claudeTaskCall
+	taskAgents := make(map[string]string) // tool_use_id -> agentId reported by its result
+	skipped, _ := eachLine(f, maxLineSize, func(raw []byte) bool {
+		var line struct {
+			Type              string `json:"type"`
+			UUID              string `json:"uuid"`
+			ParentUUID        string `json:"parentUuid"`
+			LogicalParentUUID string `json:"logicalParentUuid"` // across compaction
+			IsSidechain       bool   `json:"isSidechain"`
+		}
+		if json.Unmarshal(raw, &line) != nil {
+			return true
+		}
+		switch {
+		case line.Type == "assistant" && bytes.Contains(raw, []byte(`"name":"Task"`)):
+			tasks = append(tasks, claudeTaskCalls(raw)...)
+		case line.Type == "user" && bytes.Contains(raw, []byte(`"agentId"`)):
+			claudeTaskAgents(raw, taskAgents)
+		}
+		if line.UUID == "" {
+			return true
+		}
+		parent := line.ParentUUID
+		if parent == "" {
+			parent = line.LogicalParentUUID
+		}
+		entries = append(entries, claudeEntry{
+			uuid:    line.UUID,
+			parent:  parent,
+			message: line.Type == "user" || line.Type == "assistant",
+			side:    line.IsSidechain,
+		})
+		return true
+	})
+
+	if skipped > 0 {
+		bridgeSkippedEntries(entries)
+	}
+	mainChain := false
+	for _, e := range entries {
+		mainChain = mainChain || (e.message && !e.side)
+	}
+	if mainChain {
+		// sidechains are subagent conversations, which are shown on their
+		// own; a subagent's transcript is all sidechain
+		for i := range entries {
+			entries[i].message = entries[i].message && !entries[i].side
+		}
+	}
+	outline := &claudeOutline{subagents: matchSubagents(tasks, taskAgents, subagents)}
+	if len(entries) > 0 {
+		outline.tree = newClaudeTree(entries)
+	}
+	outlineCache.Lock()
+	outlineCache.path, outlineCache.size, outlineCache.modTime = filePath, info.Size(), info.ModTime()
+	outlineCache.outline = outline
+	outlineCache.Unlock()
+	return outline
+}
+
+// bridgeSkippedEntries links entries whose parent was on a line too long to
+// read to the entry before them, so the conversation doesn't end there.
+func bridgeSkippedEntries(entries []claudeEntry) {
+	known := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		known[e.uuid] = true
+	}
+	for i := 1; i < len(entries); i++ {
+		if p := entries[i].parent; p != "" && !known[p] {
+			entries[i].parent = entries[i-1].uuid
+		}
+	}
+}
+
+// claudeEntry is a transcript line with a uuid.
+type claudeEntry struct {
+	uuid, parent string
+	message      bool // a user or assistant message of the conversation
+	side         bool // part of a sidechain
+}
+
+// newClaudeTree links entries, given in file order.
+func newClaudeTree(entries []claudeEntry) *claudeTree {
+	t := &claudeTree{
+		parent:   make(map[string]string, len(entries)),
+		children: make(map[string][]string),
+		latest:   make(map[string]string),
+	}
+	order := make(map[string]int, len(entries))
+	for i, e := range entries {
+		t.parent[e.uuid] = e.parent
+		t.children[e.parent] = append(t.children[e.parent], e.uuid)
+		order[e.uuid] = i
+		if e.message {
+			t.last = e.uuid
+		}
+	}
+	// children are written after their parents, so walking backwards
+	// settles each subtree before its parent is reached
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if e.message && t.latest[e.uuid] == "" {
+			t.latest[e.uuid] = e.uuid
+		}
+		l := t.latest[e.uuid]
+		if l == "" || e.parent == "" {
+			continue
+		}
+		if cur := t.latest[e.parent]; cur == "" || order[l] > order[cur] {
+			t.latest[e.parent] = l
+		}
+	}
+	return t
+}
+
+// path returns the entries from the root to leaf, or to the end of the
+// active branch if leaf is empty or unknown.
+func (t *claudeTree) path(leaf string) map[string]bool {
+	if _, ok := t.parent[leaf]; !ok {
+		leaf = t.last
+	}
+	on := make(map[string]bool)
+	for u := leaf; u != "" && !on[u]; u = t.parent[u] {
+		on[u] = true
+	}
+	return on
+}
+
+// fork returns the branches at uuid if it is one of several alternatives
+// continuing the same entry, counting only those that lead to messages.
+func (t *claudeTree) fork(uuid string) *model.Fork {
+	parent := t.parent[uuid]
+	if parent == "" {
+		return nil
+	}
+	fork := &model.Fork{Branch: -1}
+	for _, c := range t.children[parent] {
+		l := t.latest[c]
+		if l == "" {
+			continue
+		}
+		if c == uuid {
+			fork.Branch = len(fork.Leaves)
+		}
+		fork.Leaves = append(fork.Leaves, l)
+	}
+	if len(fork.Leaves) < 2 || fork.Branch < 0 {
+		return nil
+	}
+	return fork
+}
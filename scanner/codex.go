@@ -10,60 +10,112 @@ import (
 	"github.com/jackwu/vibesession/model"
 )
 
-func ScanCodex() []model.Session {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil
+// ScanCodex lists the Codex sessions in each of roots, directories laid out
+// like ~/.codex, and why any rollout files were skipped. A rollout found at
+// the same place in more than one root is listed from the first.
+func ScanCodex(roots []string) ([]model.Session, []model.Diagnostic) {
+	var sessions []model.Session
+	var diags []model.Diagnostic
+	var seen rootPaths
+	for _, root := range roots {
+		found, d := scanCodexRoot(root)
+		sessions = append(sessions, seen.add(root, found)...)
+		diags = append(diags, d...)
 	}
+	return sessions, diags
+}
 
-	sessionsDir := filepath.Join(homeDir, ".codex", "sessions")
-	if _, err := os.Stat(sessionsDir); os.IsNotExist(err) {
-		return nil
+// scanCodexRoot lists the sessions in root's sessions and archived_sessions
+// directories. Sessions whose rollout holds no prompt worth showing are
+// summarized from history.jsonl instead.
+func scanCodexRoot(root string) ([]model.Session, []model.Diagnostic) {
+	sessions, diags := scanCodexDir(filepath.Join(root, "sessions"))
+	archived, d := scanCodexDir(filepath.Join(root, "archived_sessions"))
+	for i := range archived {
+		archived[i].Archived = true
+	}
+	sessions = append(sessions, archived...)
+	diags = append(diags, d...)
+
+	var history map[string]string
+	for i := range sessions {
+		s := &sessions[i]
+		s.Root = root
+		if s.Summary != "" {
+			continue
+		}
+		if history == nil {
+			history = codexHistory(filepath.Join(root, "history.jsonl"))
+		}
+		s.Summary = history[s.ID]
+		if s.Summary == "" {
+			s.Summary = "(no message)"
+		}
+	}
+	return sessions, diags
+}
+
+// scanCodexDir parses the rollout files anywhere under dir.
+func scanCodexDir(dir string) ([]model.Session, []model.Diagnostic) {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil, nil
 	}
 
 	var sessions []model.Session
+	var diags []model.Diagnostic
 
-	err = filepath.Walk(sessionsDir, func(path string, info os.FileInfo, err error) error {
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
+			diags = append(diags, *skipFile(model.SourceCodex, path, model.ReasonUnreadable, err.Error()))
 			return nil
 		}
 		if info.IsDir() || !strings.HasSuffix(info.Name(), ".jsonl") {
 			return nil
 		}
 
-		s := parseCodexSession(path, info)
+		s, diag := cachedParse(path, info, func() (*model.Session, *model.Diagnostic) {
+			return parseCodexSession(path, info)
+		})
 		if s != nil {
 			sessions = append(sessions, *s)
+		} else if diag != nil {
+			diags = append(diags, *diag)
 		}
 		return nil
 	})
 
-	if err != nil {
-		return nil
-	}
-
-	return sessions
+	return sessions, diags
 }
 
-func parseCodexSession(filePath string, info os.FileInfo) *model.Session {
+// parseCodexSession reads the session in a rollout file, or returns why
+// there is none.
+func parseCodexSession(filePath string, info os.FileInfo) (*model.Session, *model.Diagnostic) {
 	f, err := os.Open(filePath)
 	if err != nil {
-		return nil
+		return nil, skipFile(model.SourceCodex, filePath, model.ReasonUnreadable, err.Error())
 	}
 	defer f.Close()
 
 	scanner := bufio.NewScanner(f)
-	scanner.Buffer(make([]byte, 0, 256*1024), 256*1024)
+	scanner.Buffer(make([]byte, 0, headerLineSize), headerLineSize)
 
 	var sessionID string
 	var cwd string
 	var summary string
+	var meta struct {
+		CLIVersion string `json:"cli_version"`
+		Git        struct {
+			Branch string `json:"branch"`
+		} `json:"git"`
+	}
 
-	for i := 0; i < 50 && scanner.Scan(); i++ {
+	read, parsed := 0, 0
+	for ; read < 50 && scanner.Scan(); read++ {
 		var line map[string]interface{}
 		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
 			continue
 		}
+		parsed++
 
 		lineType, _ := line["type"].(string)
 
@@ -73,6 +125,12 @@ func parseCodexSession(filePath string, info os.FileInfo) *model.Session {
 				sessionID, _ = payload["id"].(string)
 				cwd, _ = payload["cwd"].(string)
 			}
+			var raw struct {
+				Payload json.RawMessage `json:"payload"`
+			}
+			if json.Unmarshal(scanner.Bytes(), &raw) == nil {
+				json.Unmarshal(raw.Payload, &meta)
+			}
 		}
 
 		// extract first real user message from response_item
@@ -99,19 +157,19 @@ func parseCodexSession(filePath string, info os.FileInfo) *model.Session {
 	}
 
 	if sessionID == "" {
-		return nil
+		return nil, headerSkipped(model.SourceCodex, filePath, scanner.Err(), read, parsed, "no session_meta with an id in the first 50 lines")
 	}
 
+	// left empty when there is no prompt: scanCodexRoot looks in the history
 	summary = truncate(summary, 120)
-	if summary == "" {
-		summary = "(no message)"
-	}
 
 	project := filepath.Base(cwd)
 	if project == "" || project == "." {
 		project = "unknown"
 	}
 
+	stats := scanCodexStats(filePath)
+	start, end := transcriptTimeSpan(filePath)
 	return &model.Session{
 		ID:       sessionID,
 		ShortID:  shortID(sessionID),
@@ -121,7 +179,49 @@ func parseCodexSession(filePath string, info os.FileInfo) *model.Session {
 		CWD:      cwd,
 		Summary:  summary,
 		FilePath: filePath,
+		Usage:    stats.usage,
+
+		// turn_context carries the model; older rollouts only name the
+		// provider, which is no model name, so Model stays empty
+		Model:      stats.model,
+		GitBranch:  meta.Git.Branch,
+		CLIVersion: meta.CLIVersion,
+
+		StartedAt:    start,
+		EndedAt:      end,
+		MessageCount: stats.messages,
+		Compactions:  stats.compactions,
+
+		Tools: stats.tools,
+		Files: stats.files,
+	}, nil
+}
+
+// codexHistory reads history.jsonl, where Codex appends every prompt, and
+// returns the first one of each session that isn't a slash command.
+func codexHistory(path string) map[string]string {
+	prompts := make(map[string]string)
+	f, err := os.Open(path)
+	if err != nil {
+		return prompts
 	}
+	defer f.Close()
+
+	eachLine(f, maxLineSize, func(line []byte) bool {
+		var entry struct {
+			SessionID string `json:"session_id"`
+			Text      string `json:"text"`
+		}
+		if json.Unmarshal(line, &entry) != nil || entry.SessionID == "" {
+			return true
+		}
+		text := strings.TrimSpace(entry.Text)
+		if _, ok := prompts[entry.SessionID]; !ok && text != "" && !strings.HasPrefix(text, "/") {
+			prompts[entry.SessionID] = truncate(text, 120)
+		}
+		return true
+	})
+	return prompts
 }
 
 // extractCodexText extracts the text from a Codex response_item payload.
@@ -0,0 +1,104 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// codexToolCall is a tool invocation response item of a Codex rollout:
+//
+//	function_call     {"name":"shell","arguments":"{\"command\":[\"bash\",\"-lc\",\"go test\"]}"}
+//	function_call     {"name":"exec_command","arguments":"{\"cmd\":\"go test\"}"}
+//	custom_tool_call  {"name":"apply_patch","input":"*** Begin Patch\n..."}
+//	local_shell_call  {"action":{"type":"exec","command":["ls"]}}
+type codexToolCall struct {
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	CallID    string `json:"call_id"`
+	Arguments string `json:"arguments"` // JSON, for function_call
+	Input     string `json:"input"`     // free-form, for custom_tool_call
+	Action    struct {
+		Command []string `json:"command"`
+	} `json:"action"` // for local_shell_call
+}
+
+func isCodexToolCallType(t string) bool {
+	return t == "function_call" || t == "custom_tool_call" || t == "local_shell_call"
+}
+
+// codexShellTools are the function_call names that run a command. Older
+// rollouts pass an argv as "command"; shell_command and exec_command, in
+// newer ones, pass a command line as "command" or "cmd".
+var codexShellTools = map[string]bool{
+	"shell":          true,
+	"container.exec": true,
+	"shell_command":  true,
+	"exec_command":   true,
+}
+
+// shellCommand returns the command line of a shell call, unwrapping the
+// usual ["bash", "-lc", "<script>"] form.
+func (c codexToolCall) shellCommand() (string, bool) {
+	argv := c.Action.Command
+	if c.Type != "local_shell_call" {
+		if !codexShellTools[c.Name] {
+			return "", false
+		}
+		var args struct {
+			Command json.RawMessage `json:"command"`
+			Cmd     string          `json:"cmd"`
+		}
+		if json.Unmarshal([]byte(c.Arguments), &args) != nil {
+			return "", false
+		}
+		var line string
+		switch {
+		case args.Cmd != "":
+			return args.Cmd, true
+		case json.Unmarshal(args.Command, &line) == nil:
+			return line, true
+		case json.Unmarshal(args.Command, &argv) != nil:
+			return "", false
+		}
+	}
+	if len(argv) == 3 && (argv[1] == "-lc" || argv[1] == "-c") {
+		return argv[2], true
+	}
+	return strings.Join(argv, " "), true
+}
+
+// format summarizes the call the way formatToolCall does for Claude,
+// e.g. "Shell: go test ./..." or "Patch: scanner/codex.go".
+func (c codexToolCall) format() string {
+	if c.Name == "apply_patch" {
+		return formatPatch(c.Input + c.Arguments)
+	}
+	if cmd, ok := c.shellCommand(); ok {
+		// apply_patch is also run through the shell as a heredoc
+		if strings.HasPrefix(strings.TrimSpace(cmd), "apply_patch") {
+			return formatPatch(cmd)
+		}
+		return fmt.Sprintf("Shell: %s", truncateStr(cmd, 60))
+	}
+	if c.Name == "update_plan" {
+		return "Plan"
+	}
+	return formatToolCall(c.Name, json.RawMessage(c.Arguments))
+}
+
+// formatPatch names the files an apply_patch envelope touches.
+func formatPatch(patch string) string {
+	var files []string
+	for _, m := range patchFileRe.FindAllStringSubmatch(patch, -1) {
+		files = append(files, shortPath(strings.TrimSpace(m[1])))
+	}
+	switch len(files) {
+	case 0:
+		return "Patch"
+	case 1:
+		return "Patch: " + files[0]
+	default:
+		return fmt.Sprintf("Patch: %s (+%d more)", files[0], len(files)-1)
+	}
+}
@@ -0,0 +1,37 @@
+package scanner
+
+import (
+	"strings"
+
+	"github.com/jackwu/vibesession/model"
+)
+
+// claudeCompactPreamble starts the summary message Claude Code continues
+// from after compacting.
+const claudeCompactPreamble = "This session is being continued from a previous conversation"
+
+// appendCompaction records a compaction of the agent's context.
+func appendCompaction(messages []model.Message, idx *int, c model.Compaction) []model.Message {
+	messages = append(messages, model.Message{Role: "system", Index: *idx, Compaction: &c})
+	*idx++
+	return messages
+}
+
+// addCompactionSummary attaches summary to the compaction just recorded,
+// or records a compaction if the transcript only kept the summary.
+func addCompactionSummary(messages []model.Message, idx *int, summary string) []model.Message {
+	summary = strings.TrimSpace(summary)
+	if strings.HasPrefix(summary, claudeCompactPreamble) {
+		// "... ran out of context. The conversation is summarized below:"
+		if _, rest, ok := strings.Cut(summary, "\n"); ok {
+			summary = strings.TrimSpace(rest)
+		}
+	}
+	if n := len(messages); n > 0 {
+		if c := messages[n-1].Compaction; c != nil && c.Summary == "" {
+			c.Summary = summary
+			return messages
+		}
+	}
+	return appendCompaction(messages, idx, model.Compaction{Summary: summary})
+}
@@ -0,0 +1,37 @@
+package scanner
+
+import (
+	"bufio"
+	"fmt"
+
+	"github.com/jackwu/vibesession/model"
+)
+
+// headerLineSize caps the lines read while looking for a session's
+// metadata at the top of a transcript.
+const headerLineSize = 256 * 1024
+
+// skipFile records why the file at path yielded no session.
+func skipFile(src model.Source, path string, reason model.Reason, detail string) *model.Diagnostic {
+	return &model.Diagnostic{Source: src, Path: path, Reason: reason, Detail: detail}
+}
+
+// headerSkipped explains why the first lines of a JSONL transcript held no
+// session: err is what ended the scan early, if anything, read how many
+// lines were read and parsed how many of them were JSON. want describes
+// what was looked for, e.g. "no sessionId in the first 10 lines".
+func headerSkipped(src model.Source, path string, err error, read, parsed int, want string) *model.Diagnostic {
+	switch {
+	case err == bufio.ErrTooLong:
+		return skipFile(src, path, model.ReasonTooLong, fmt.Sprintf("line %d is over %s", read+1, formatSize(headerLineSize)))
+	case err != nil:
+		return skipFile(src, path, model.ReasonUnreadable, err.Error())
+	case read == 0:
+		return skipFile(src, path, model.ReasonEmpty, "")
+	case parsed == 0 && read == 1:
+		return skipFile(src, path, model.ReasonNotJSON, "the first line doesn't parse")
+	case parsed == 0:
+		return skipFile(src, path, model.ReasonNotJSON, fmt.Sprintf("none of the first %d lines parse", read))
+	}
+	return skipFile(src, path, model.ReasonNoSession, want)
+}
@@ -0,0 +1,683 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackwu/vibesession/model"
+)
+
+// Gemini CLI keeps per-project data under ~/.gemini/tmp/<sha256(project root)>/:
+//
+//	chats/session-*.json  full recorded conversations (one JSON document each)
+//	checkpoint-<tag>.json conversations saved with /chat save <tag>
+//	logs.json             user prompts only; the only record for older CLI versions
+
+// GeminiCheckpointPrefix marks session IDs that refer to a /chat save tag.
+const GeminiCheckpointPrefix = "checkpoint:"
+
+type geminiChat struct {
+	SessionID   string          `json:"sessionId"`
+	ProjectHash string          `json:"projectHash"`
+	StartTime   string          `json:"startTime"`
+	LastUpdated string          `json:"lastUpdated"`
+	Messages    []geminiMessage `json:"messages"`
+}
+
+type geminiMessage struct {
+	Type      string           `json:"type"` // "user", "gemini", "info", "error", ...
+	Content   json.RawMessage  `json:"content"`
+	Timestamp string           `json:"timestamp"`
+	Model     string           `json:"model"`
+	Tokens    *geminiTokens    `json:"tokens"`
+	ToolCalls []geminiToolCall `json:"toolCalls"`
+	Thoughts  []struct {
+		Subject     string `json:"subject"`
+		Description string `json:"description"`
+	} `json:"thoughts"`
+}
+
+// geminiToolCall is a tool call recorded in a chat, together with its result.
+type geminiToolCall struct {
+	ID            string          `json:"id"`
+	Name          string          `json:"name"`
+	Args          json.RawMessage `json:"args"`
+	Status        string          `json:"status"`        // "success", "error", "cancelled"
+	ResultDisplay json.RawMessage `json:"resultDisplay"` // text, or an object such as a file diff
+	Result        []struct {
+		FunctionResponse *geminiFunctionResponse `json:"functionResponse"`
+	} `json:"result"`
+}
+
+type geminiFunctionResponse struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Response struct {
+		Output string `json:"output"`
+		Error  string `json:"error"`
+	} `json:"response"`
+}
+
+func (tc geminiToolCall) toModel() model.ToolCall {
+	call := model.ToolCall{
+		ID:      tc.ID,
+		Name:    tc.Name,
+		Summary: formatGeminiToolCall(tc.Name, tc.Args),
+		Input:   string(tc.Args),
+	}
+	if tc.Status == "" {
+		return call
+	}
+	call.HasResult = true
+	call.IsError = tc.Status == "error"
+	var display string
+	if json.Unmarshal(tc.ResultDisplay, &display) != nil || display == "" {
+		for _, r := range tc.Result {
+			if fr := r.FunctionResponse; fr != nil {
+				display = fr.Response.Output + fr.Response.Error
+			}
+		}
+	}
+	call.Output = toolOutputExcerpt(display)
+	return call
+}
+
+// geminiTokens is the per-message token count of a recorded chat.
+// Input includes the cached tokens; thoughts are billed as output.
+type geminiTokens struct {
+	Input    int `json:"input"`
+	Output   int `json:"output"`
+	Cached   int `json:"cached"`
+	Thoughts int `json:"thoughts"`
+}
+
+func (t *geminiTokens) toModel() model.Usage {
+	if t == nil {
+		return model.Usage{}
+	}
+	return model.Usage{
+		Input:     t.Input - t.Cached,
+		Output:    t.Output + t.Thoughts,
+		CacheRead: t.Cached,
+	}
+}
+
+// geminiContent is the Gemini API content format used by checkpoints.
+type geminiContent struct {
+	Role  string `json:"role"` // "user" or "model"
+	Parts []struct {
+		Text         string `json:"text"`
+		Thought      bool   `json:"thought"` // the text is the model's reasoning
+		FunctionCall *struct {
+			ID   string          `json:"id"`
+			Name string          `json:"name"`
+			Args json.RawMessage `json:"args"`
+		} `json:"functionCall"`
+		FunctionResponse *geminiFunctionResponse `json:"functionResponse"`
+	} `json:"parts"`
+}
+
+type geminiLogEntry struct {
+	SessionID string `json:"sessionId"`
+	Type      string `json:"type"`
+	Message   string `json:"message"`
+	Timestamp string `json:"timestamp"`
+}
+
+// ScanGemini lists the Gemini CLI sessions, and why any chat or checkpoint
+// files were skipped.
+func ScanGemini() ([]model.Session, []model.Diagnostic) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, nil
+	}
+
+	tmpDir := filepath.Join(homeDir, ".gemini", "tmp")
+	projectEntries, err := os.ReadDir(tmpDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, []model.Diagnostic{*skipFile(model.SourceGemini, tmpDir, model.ReasonUnreadable, err.Error())}
+	}
+
+	var sessions []model.Session
+	var diags []model.Diagnostic
+
+	for _, projEntry := range projectEntries {
+		if !projEntry.IsDir() {
+			continue
+		}
+		projPath := filepath.Join(tmpDir, projEntry.Name())
+		cwd := geminiProjectRoot(projPath)
+
+		// recorded chats
+		chatFiles, _ := filepath.Glob(filepath.Join(projPath, "chats", "session-*.json"))
+		chatIDs := make(map[string]bool)
+		for _, path := range chatFiles {
+			info, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			s, diag := cachedParse(path, info, func() (*model.Session, *model.Diagnostic) {
+				return parseGeminiChat(path, info)
+			})
+			if s == nil {
+				if diag != nil {
+					diags = append(diags, *diag)
+				}
+				continue
+			}
+			chatIDs[s.ID] = true
+			sessions = append(sessions, withGeminiProject(*s, cwd, projEntry.Name()))
+		}
+
+		// saved checkpoints
+		checkpoints, _ := filepath.Glob(filepath.Join(projPath, "checkpoint-*.json"))
+		for _, path := range checkpoints {
+			info, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			s, diag := cachedParse(path, info, func() (*model.Session, *model.Diagnostic) {
+				return parseGeminiCheckpoint(path, info)
+			})
+			if s == nil {
+				if diag != nil {
+					diags = append(diags, *diag)
+				}
+				continue
+			}
+			sessions = append(sessions, withGeminiProject(*s, cwd, projEntry.Name()))
+		}
+
+		// prompt logs, for sessions without a recorded chat
+		for _, s := range parseGeminiLogs(filepath.Join(projPath, "logs.json"), chatIDs) {
+			sessions = append(sessions, withGeminiProject(s, cwd, projEntry.Name()))
+		}
+	}
+
+	return sessions, diags
+}
+
+// geminiProjectRoot returns the project directory of a hashed project dir,
+// which newer CLI versions write to .project_root, or "" if it's unknown.
+func geminiProjectRoot(projPath string) string {
+	data, err := os.ReadFile(filepath.Join(projPath, ".project_root"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// withGeminiProject returns s, which may be shared with the index, with its
+// project filled in.
+func withGeminiProject(s model.Session, cwd, hash string) model.Session {
+	s.CWD = cwd
+	s.Project = filepath.Base(cwd)
+	if cwd == "" {
+		// unknown root: show a short form of the project hash instead
+		s.Project = shortID(hash)
+	}
+	return s
+}
+
+func parseGeminiChat(filePath string, info os.FileInfo) (*model.Session, *model.Diagnostic) {
+	data, diag := readGeminiJSON(filePath)
+	if diag != nil {
+		return nil, diag
+	}
+	var chat geminiChat
+	if err := json.Unmarshal(data, &chat); err != nil {
+		return nil, skipFile(model.SourceGemini, filePath, model.ReasonNotJSON, err.Error())
+	}
+	if chat.SessionID == "" {
+		return nil, skipFile(model.SourceGemini, filePath, model.ReasonNoSession, "no sessionId")
+	}
+
+	var stats sessionStats
+	for _, m := range chat.Messages {
+		stats.addUsage(m.Model, m.Tokens.toModel())
+		if m.Model != "" {
+			stats.model = m.Model
+		}
+		for _, tc := range m.ToolCalls {
+			label, _, _ := strings.Cut(formatGeminiToolCall(tc.Name, tc.Args), ":")
+			stats.addTool(label)
+			stats.addToolFiles(tc.Args)
+		}
+	}
+
+	summary := ""
+	for _, m := range chat.Messages {
+		if m.Type == "user" {
+			if text := geminiText(m.Content); text != "" && !strings.HasPrefix(text, "/") {
+				summary = text
+				break
+			}
+		}
+	}
+	summary = truncate(summary, 120)
+	if summary == "" {
+		summary = "(no message)"
+	}
+
+	return &model.Session{
+		ID:       chat.SessionID,
+		ShortID:  shortID(chat.SessionID),
+		Source:   model.SourceGemini,
+		Time:     info.ModTime(),
+		Summary:  summary,
+		FilePath: filePath,
+		Usage:    stats.usage,
+		Model:    stats.model,
+
+		StartedAt:    parseGeminiTime(chat.StartTime),
+		EndedAt:      parseGeminiTime(chat.LastUpdated),
+		MessageCount: len(parseGeminiChatMessages(data)),
+
+		Tools: stats.tools,
+		Files: stats.files,
+	}, nil
+}
+
+// readGeminiJSON reads a chat or checkpoint file whole, or returns why it
+// can't be used.
+func readGeminiJSON(filePath string) ([]byte, *model.Diagnostic) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, skipFile(model.SourceGemini, filePath, model.ReasonUnreadable, err.Error())
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil, skipFile(model.SourceGemini, filePath, model.ReasonEmpty, "")
+	}
+	return data, nil
+}
+
+// parseGeminiTime parses an ISO timestamp, returning zero if it's malformed.
+func parseGeminiTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+func parseGeminiCheckpoint(filePath string, info os.FileInfo) (*model.Session, *model.Diagnostic) {
+	data, diag := readGeminiJSON(filePath)
+	if diag != nil {
+		return nil, diag
+	}
+	var history []geminiContent
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, skipFile(model.SourceGemini, filePath, model.ReasonNotJSON, err.Error())
+	}
+
+	tag := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(filePath), "checkpoint-"), ".json")
+	summary := ""
+	for _, c := range history {
+		if c.Role != "user" {
+			continue
+		}
+		for _, p := range c.Parts {
+			// the first user turn is usually the CLI's injected setup context
+			if p.Text != "" && !strings.HasPrefix(p.Text, "This is the Gemini CLI") {
+				summary = p.Text
+				break
+			}
+		}
+		if summary != "" {
+			break
+		}
+	}
+
+	return &model.Session{
+		ID:       GeminiCheckpointPrefix + tag,
+		ShortID:  tag,
+		Source:   model.SourceGemini,
+		Time:     info.ModTime(),
+		Summary:  truncate("[saved] "+summary, 120),
+		FilePath: filePath,
+
+		MessageCount: len(parseGeminiCheckpointMessages(data)),
+	}, nil
+}
+
+// parseGeminiLogs groups logs.json prompts by session, skipping sessions in skip.
+func parseGeminiLogs(filePath string, skip map[string]bool) []model.Session {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil
+	}
+	var entries []geminiLogEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil
+	}
+
+	bySession := make(map[string]*model.Session)
+	var order []string
+	for _, e := range entries {
+		if e.SessionID == "" || skip[e.SessionID] || e.Type != "user" {
+			continue
+		}
+		s, ok := bySession[e.SessionID]
+		if !ok {
+			s = &model.Session{
+				ID:       e.SessionID,
+				ShortID:  shortID(e.SessionID),
+				Source:   model.SourceGemini,
+				Time:     info.ModTime(),
+				FilePath: filePath,
+			}
+			bySession[e.SessionID] = s
+			order = append(order, e.SessionID)
+		}
+		s.MessageCount++
+		if t := parseGeminiTime(e.Timestamp); !t.IsZero() {
+			if s.StartedAt.IsZero() || t.Before(s.StartedAt) {
+				s.StartedAt = t
+			}
+			if t.After(s.EndedAt) {
+				s.EndedAt = t
+			}
+		}
+		if s.Summary == "" && e.Message != "" && !strings.HasPrefix(e.Message, "/") {
+			s.Summary = truncate(e.Message, 120)
+		}
+	}
+
+	var sessions []model.Session
+	for _, id := range order {
+		s := bySession[id]
+		if s.Summary == "" {
+			s.Summary = "(no message)"
+		}
+		sessions = append(sessions, *s)
+	}
+	return sessions
+}
+
+// ParseGeminiMessages reads the conversation of a Gemini session.
+// The file layout is told apart by name: chats, checkpoints, or logs.json.
+func ParseGeminiMessages(filePath, sessionID string) []model.Message {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil
+	}
+
+	switch {
+	case filepath.Base(filePath) == "logs.json":
+		return parseGeminiLogMessages(data, sessionID)
+	case strings.HasPrefix(sessionID, GeminiCheckpointPrefix):
+		return parseGeminiCheckpointMessages(data)
+	default:
+		return parseGeminiChatMessages(data)
+	}
+}
+
+func parseGeminiChatMessages(data []byte) []model.Message {
+	var chat geminiChat
+	if err := json.Unmarshal(data, &chat); err != nil {
+		return nil
+	}
+
+	var messages []model.Message
+	idx := 0
+	for _, m := range chat.Messages {
+		var role string
+		switch m.Type {
+		case "user":
+			role = "user"
+		case "gemini":
+			role = "assistant"
+		default:
+			continue
+		}
+
+		var thoughts []string
+		for _, t := range m.Thoughts {
+			thought := strings.TrimSpace(t.Description)
+			if t.Subject != "" {
+				thought = "**" + t.Subject + "**\n" + thought
+			}
+			thoughts = append(thoughts, thought)
+		}
+		if role == "assistant" && len(thoughts) > 0 {
+			messages = appendThinking(messages, &idx, strings.Join(thoughts, "\n\n"))
+		}
+
+		text := geminiText(m.Content)
+		var tools []model.ToolCall
+		for _, tc := range m.ToolCalls {
+			tools = append(tools, tc.toModel())
+		}
+		if text == "" && len(tools) == 0 {
+			continue
+		}
+		messages = appendMessage(messages, &idx, role, text, tools, m.Tokens.toModel())
+	}
+	return messages
+}
+
+func parseGeminiCheckpointMessages(data []byte) []model.Message {
+	var history []geminiContent
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil
+	}
+
+	var messages []model.Message
+	idx := 0
+	calls := make(toolCallIndex)
+	// older checkpoints have no call IDs; those calls get one of their own
+	// and are paired with responses to the same tool in order
+	unanswered := make(map[string][]string) // tool name -> IDs given
+	given := 0
+	for _, c := range history {
+		role := "user"
+		if c.Role == "model" {
+			role = "assistant"
+		}
+		var texts, thoughts []string
+		var tools []model.ToolCall
+		for _, p := range c.Parts {
+			if p.Text != "" && p.Thought {
+				thoughts = append(thoughts, p.Text)
+			} else if p.Text != "" {
+				texts = append(texts, p.Text)
+			}
+			if fc := p.FunctionCall; fc != nil {
+				id := fc.ID
+				if id == "" {
+					given++
+					id = fmt.Sprintf("%s#%d", fc.Name, given)
+					unanswered[fc.Name] = append(unanswered[fc.Name], id)
+				}
+				tools = append(tools, model.ToolCall{
+					ID:      id,
+					Name:    fc.Name,
+					Summary: formatGeminiToolCall(fc.Name, fc.Args),
+					Input:   string(fc.Args),
+				})
+			}
+			if fr := p.FunctionResponse; fr != nil {
+				id := fr.ID
+				if id == "" {
+					queue := unanswered[fr.Name]
+					if len(queue) == 0 {
+						continue
+					}
+					id, unanswered[fr.Name] = queue[0], queue[1:]
+				}
+				calls.setResult(messages, id, fr.Response.Output+fr.Response.Error, fr.Response.Error != "")
+			}
+		}
+		if role == "assistant" && len(thoughts) > 0 {
+			messages = appendThinking(messages, &idx, strings.Join(thoughts, "\n\n"))
+		}
+		// user turns that only carry function responses are tool results
+		if len(texts) == 0 && len(tools) == 0 {
+			continue
+		}
+		messages = appendMessage(messages, &idx, role, strings.Join(texts, "\n"), tools, model.Usage{})
+		// appendMessage may have merged into the previous message
+		last := len(messages) - 1
+		for ti := len(messages[last].ToolCalls) - len(tools); ti < len(messages[last].ToolCalls); ti++ {
+			calls[messages[last].ToolCalls[ti].ID] = toolCallPos{last, ti}
+		}
+	}
+	return messages
+}
+
+func parseGeminiLogMessages(data []byte, sessionID string) []model.Message {
+	var entries []geminiLogEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp < entries[j].Timestamp
+	})
+
+	var messages []model.Message
+	for _, e := range entries {
+		if e.SessionID != sessionID || e.Type != "user" || e.Message == "" {
+			continue
+		}
+		messages = append(messages, model.Message{
+			Role:  "user",
+			Text:  e.Message,
+			Index: len(messages),
+		})
+	}
+	return messages
+}
+
+// appendMessage appends a message, merging consecutive assistant turns
+// the same way the Claude and Codex parsers do.
+func appendMessage(messages []model.Message, idx *int, role, text string, tools []model.ToolCall, usage model.Usage) []model.Message {
+	if role == "assistant" && len(messages) > 0 && messages[len(messages)-1].Role == "assistant" {
+		prev := &messages[len(messages)-1]
+		if text != "" {
+			if prev.Text != "" {
+				prev.Text += "\n" + text
+			} else {
+				prev.Text = text
+			}
+		}
+		prev.ToolCalls = append(prev.ToolCalls, tools...)
+		prev.Usage = prev.Usage.Add(usage)
+		return messages
+	}
+	messages = append(messages, model.Message{
+		Role:      role,
+		Text:      text,
+		ToolCalls: tools,
+		Index:     *idx,
+		Usage:     usage,
+	})
+	*idx++
+	return messages
+}
+
+// appendThinking adds the model's reasoning to the assistant message being
+// built, starting one if the previous message isn't from the assistant.
+func appendThinking(messages []model.Message, idx *int, thinking string) []model.Message {
+	if len(messages) == 0 || messages[len(messages)-1].Role != "assistant" {
+		messages = append(messages, model.Message{Role: "assistant", Index: *idx})
+		*idx++
+	}
+	prev := &messages[len(messages)-1]
+	if prev.Thinking != "" {
+		prev.Thinking += "\n\n"
+	}
+	prev.Thinking += thinking
+	return messages
+}
+
+// geminiText extracts text from message content, which is either a plain
+// string or an array of parts with "text" fields.
+func geminiText(raw json.RawMessage) string {
+	var str string
+	if err := json.Unmarshal(raw, &str); err == nil {
+		return strings.TrimSpace(str)
+	}
+	var parts []struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return ""
+	}
+	var texts []string
+	for _, p := range parts {
+		if p.Text != "" {
+			texts = append(texts, p.Text)
+		}
+	}
+	return strings.TrimSpace(strings.Join(texts, "\n"))
+}
+
+// formatGeminiToolCall summarizes a Gemini tool call with the same labels
+// formatToolCall uses for Claude, so both read alike in the viewer.
+func formatGeminiToolCall(name string, args json.RawMessage) string {
+	var params map[string]interface{}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return name
+	}
+	str := func(keys ...string) string {
+		for _, k := range keys {
+			if v, ok := params[k].(string); ok && v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+
+	switch name {
+	case "read_file", "read_many_files":
+		if p := str("absolute_path", "file_path", "path"); p != "" {
+			return fmt.Sprintf("Read: %s", shortPath(p))
+		}
+	case "write_file":
+		if p := str("file_path", "absolute_path"); p != "" {
+			return fmt.Sprintf("Write: %s", shortPath(p))
+		}
+	case "replace":
+		if p := str("file_path", "absolute_path"); p != "" {
+			return fmt.Sprintf("Edit: %s", shortPath(p))
+		}
+	case "run_shell_command":
+		if cmd := str("command"); cmd != "" {
+			return fmt.Sprintf("Shell: %s", truncateStr(cmd, 60))
+		}
+	case "glob":
+		if p := str("pattern"); p != "" {
+			return fmt.Sprintf("Glob: %s", p)
+		}
+	case "search_file_content":
+		if p := str("pattern"); p != "" {
+			return fmt.Sprintf("Grep: %s", truncateStr(p, 40))
+		}
+	case "list_directory":
+		if p := str("path", "dir_path"); p != "" {
+			return fmt.Sprintf("List: %s", shortPath(p))
+		}
+	case "google_web_search":
+		if q := str("query"); q != "" {
+			return fmt.Sprintf("WebSearch: %s", truncateStr(q, 50))
+		}
+	case "web_fetch":
+		if p := str("prompt", "url"); p != "" {
+			return fmt.Sprintf("WebFetch: %s", truncateStr(p, 60))
+		}
+	}
+
+	return formatToolCall(name, args)
+}
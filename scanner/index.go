@@ -0,0 +1,128 @@
+package scanner
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/jackwu/vibesession/model"
+)
+
+// indexVersion must be bumped whenever model.Session or the parse logic
+// changes, so entries written by an older vbs are rebuilt instead of trusted.
+const indexVersion = 17
+
+// indexEntry caches the parse result of one transcript file.
+// A nil Session records a file that was parsed but yielded no session,
+// so it isn't re-read on every launch either; Skipped says why.
+type indexEntry struct {
+	Size    int64             `json:"size"`
+	ModTime int64             `json:"mtime"` // unix nanoseconds
+	Session *model.Session    `json:"session"`
+	Skipped *model.Diagnostic `json:"skipped,omitempty"`
+}
+
+type indexFile struct {
+	Version int                   `json:"version"`
+	Entries map[string]indexEntry `json:"entries"`
+}
+
+// sessionIndex is shared by all scanners, which may run concurrently.
+var sessionIndex struct {
+	sync.Mutex
+	once    sync.Once
+	entries map[string]indexEntry // loaded from disk
+	seen    map[string]indexEntry // entries looked up or stored this run
+	dirty   bool
+}
+
+func indexPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".cache", "vbs", "index.json")
+}
+
+func loadIndex() {
+	sessionIndex.entries = make(map[string]indexEntry)
+	sessionIndex.seen = make(map[string]indexEntry)
+
+	data, err := os.ReadFile(indexPath())
+	if err != nil {
+		sessionIndex.dirty = true
+		return
+	}
+	var idx indexFile
+	// a corrupt or outdated index is simply rebuilt from scratch
+	if err := json.Unmarshal(data, &idx); err != nil || idx.Version != indexVersion || idx.Entries == nil {
+		sessionIndex.dirty = true
+		return
+	}
+	sessionIndex.entries = idx.Entries
+}
+
+// cachedParse returns the indexed session for filePath if the file's size and
+// mtime are unchanged, otherwise it calls parse and records the result.
+// For a file without a session it returns why it was skipped instead.
+func cachedParse(filePath string, info os.FileInfo, parse func() (*model.Session, *model.Diagnostic)) (*model.Session, *model.Diagnostic) {
+	sessionIndex.once.Do(loadIndex)
+
+	size := info.Size()
+	mtime := info.ModTime().UnixNano()
+
+	sessionIndex.Lock()
+	e, ok := sessionIndex.entries[filePath]
+	if ok && e.Size == size && e.ModTime == mtime {
+		sessionIndex.seen[filePath] = e
+		sessionIndex.Unlock()
+		if e.Session == nil {
+			return nil, e.Skipped
+		}
+		s := *e.Session
+		s.Time = info.ModTime()
+		return &s, nil
+	}
+	sessionIndex.Unlock()
+
+	s, diag := parse()
+
+	sessionIndex.Lock()
+	sessionIndex.seen[filePath] = indexEntry{Size: size, ModTime: mtime, Session: s, Skipped: diag}
+	sessionIndex.dirty = true
+	sessionIndex.Unlock()
+	return s, diag
+}
+
+// SaveIndex writes the entries seen during this run back to disk.
+// Files that were not visited (deleted transcripts) are dropped.
+func SaveIndex() error {
+	sessionIndex.once.Do(loadIndex)
+
+	sessionIndex.Lock()
+	defer sessionIndex.Unlock()
+
+	if !sessionIndex.dirty && len(sessionIndex.seen) == len(sessionIndex.entries) {
+		return nil
+	}
+
+	data, err := json.Marshal(indexFile{Version: indexVersion, Entries: sessionIndex.seen})
+	if err != nil {
+		return err
+	}
+
+	path := indexPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// atomic write (tmp -> rename) so a crash never leaves a half-written index
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	sessionIndex.entries = sessionIndex.seen
+	sessionIndex.dirty = false
+	return nil
+}
@@ -0,0 +1,93 @@
+package scanner
+
+import (
+	"bufio"
+	"io"
+)
+
+// maxLineSize caps the lines read by full-file passes. Longer lines (usually
+// huge tool outputs) are skipped instead of ending the pass.
+const maxLineSize = 10 * 1024 * 1024
+
+// lineReader reads the lines of a transcript, skipping lines longer than
+// max without holding them in memory, and keeps track of line offsets.
+type lineReader struct {
+	br     *bufio.Reader
+	max    int
+	offset int64 // where the next line starts
+	buf    []byte
+}
+
+// newLineReader reads lines from r, which is positioned at offset.
+func newLineReader(r io.Reader, offset int64, max int) *lineReader {
+	return &lineReader{br: bufio.NewReaderSize(r, 64*1024), max: max, offset: offset}
+}
+
+// next returns the next line without its line ending, and the offset it
+// starts at. A line longer than max is skipped: line is nil and skipped is
+// its size in bytes. The line is only valid until the next call. next
+// returns io.EOF after the last line.
+func (lr *lineReader) next() (line []byte, offset, skipped int64, err error) {
+	offset = lr.offset
+	lr.buf = lr.buf[:0]
+	var size int64
+	tooLong := false
+
+	for {
+		chunk, err := lr.br.ReadSlice('\n')
+		size += int64(len(chunk))
+		if !tooLong {
+			if len(lr.buf)+len(chunk) > lr.max+1 {
+				tooLong = true
+				lr.buf = lr.buf[:0]
+			} else {
+				lr.buf = append(lr.buf, chunk...)
+			}
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		lr.offset += size
+		switch {
+		case err != nil && err != io.EOF:
+			return nil, offset, 0, err
+		case size == 0:
+			return nil, offset, 0, io.EOF
+		case tooLong:
+			return nil, offset, size, nil
+		}
+
+		line = lr.buf
+		if line[len(line)-1] == '\n' {
+			line = line[:len(line)-1]
+		}
+		if len(line) > 0 && line[len(line)-1] == '\r' {
+			line = line[:len(line)-1]
+		}
+		return line, offset, 0, nil
+	}
+}
+
+// eachLine calls fn for every line of r that fits in maxLen bytes, until fn
+// returns false. The slice passed to fn is only valid during the call.
+// It returns the number of oversized lines that were skipped.
+func eachLine(r io.Reader, maxLen int, fn func(line []byte) bool) (int, error) {
+	lr := newLineReader(r, 0, maxLen)
+	skipped := 0
+	for {
+		line, _, size, err := lr.next()
+		if err == io.EOF {
+			return skipped, nil
+		}
+		if err != nil {
+			return skipped, err
+		}
+		if size > 0 {
+			skipped++
+			continue
+		}
+		if !fn(line) {
+			return skipped, nil
+		}
+	}
+}
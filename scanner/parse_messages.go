@@ -1,71 +1,159 @@
 package scanner
 
 import (
-	"bufio"
 	"encoding/json"
-	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
 	"github.com/jackwu/vibesession/model"
 )
 
-// ParseMessages reads a session JSONL file and returns parsed conversation messages.
-func ParseMessages(filePath string, source model.Source) []model.Message {
-	switch source {
-	case model.SourceClaude:
-		return parseClaudeMessages(filePath)
-	case model.SourceCodex:
-		return parseCodexMessages(filePath)
-	default:
-		return nil
-	}
+// ParseClaudeMessages reads a Claude session JSONL file and returns parsed conversation messages.
+// Task tool calls are linked to the transcripts in subagents that they spawned.
+func ParseClaudeMessages(filePath string, subagents []model.Session) []model.Message {
+	return ParseClaudeBranch(filePath, subagents, "")
+}
+
+// ParseClaudeBranch is ParseClaudeMessages for the branch of the conversation
+// ending at the entry with uuid leaf. Abandoned branches are left out; the
+// messages where the conversation forked carry the alternatives.
+func ParseClaudeBranch(filePath string, subagents []model.Session, leaf string) []model.Message {
+	var messages []model.Message
+	StreamClaudeMessages(filePath, subagents, leaf, model.Cursor{}, collectMessages(&messages))
+	return messages
 }
 
-func parseClaudeMessages(filePath string) []model.Message {
+// StreamClaudeMessages reads the branch of a Claude conversation ending at
+// leaf ("" for the active one), starting at from, and passes the messages to
+// emit a batch at a time until emit returns false. Each batch ends where
+// reading can resume, at next; next is nil for the last one. Lines too long
+// to read are skipped and noted in their place.
+func StreamClaudeMessages(filePath string, subagents []model.Session, leaf string, from model.Cursor, emit func(messages []model.Message, next *model.Cursor) bool) {
+	outline := readClaudeOutline(filePath, subagents)
+	tree := outline.tree
+	var onPath map[string]bool
+	if tree != nil {
+		onPath = tree.path(leaf)
+	}
+
 	f, err := os.Open(filePath)
 	if err != nil {
-		return nil
+		emit(nil, nil)
+		return
 	}
 	defer f.Close()
-
-	sc := bufio.NewScanner(f)
-	sc.Buffer(make([]byte, 0, 256*1024), 10*1024*1024) // 10MB to handle large tool outputs
+	if _, err := f.Seek(from.Offset, io.SeekStart); err != nil {
+		emit(nil, nil)
+		return
+	}
+	lr := newLineReader(f, from.Offset, maxLineSize)
 
 	var messages []model.Message
-	idx := 0
+	idx := from.Index
+	calls := make(toolCallIndex)
+	usageSeen := make(claudeUsageTracker)
+	var pendingUsage model.Usage // usage of lines with nothing to display
+	var pendingFork *model.Fork  // fork to mark on the next message shown
+	var skipped skippedLines
+
+	for {
+		raw, offset, size, err := lr.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			messages = appendNote(messages, &idx, "(parse error: some messages may be missing)")
+			break
+		}
+		if size > 0 {
+			skipped.add(size)
+			continue
+		}
 
-	for sc.Scan() {
 		var line struct {
 			Type    string `json:"type"`
+			UUID    string `json:"uuid"`
+			Subtype string `json:"subtype"`
 			Message struct {
+				ID      string          `json:"id"`
 				Role    string          `json:"role"`
 				Content json.RawMessage `json:"content"`
+				Usage   *claudeUsage    `json:"usage"`
 			} `json:"message"`
+			IsCompactSummary bool `json:"isCompactSummary"`
+			CompactMetadata  struct {
+				Trigger   string `json:"trigger"`
+				PreTokens int    `json:"preTokens"`
+			} `json:"compactMetadata"`
 		}
-		if err := json.Unmarshal(sc.Bytes(), &line); err != nil {
+		if err := json.Unmarshal(raw, &line); err != nil {
 			continue
 		}
+		// nothing carries over from earlier lines, so reading can resume here
+		resumable := pendingFork == nil && pendingUsage.Total() == 0 && skipped.lines == 0
+		messages = skipped.flush(messages, &idx)
+		if onPath != nil && line.UUID != "" {
+			if !onPath[line.UUID] {
+				continue // another branch
+			}
+			if fork := tree.fork(line.UUID); fork != nil {
+				pendingFork = fork
+			}
+		}
 
 		switch line.Type {
+		case "system":
+			if line.Subtype == "compact_boundary" {
+				messages = appendCompaction(messages, &idx, model.Compaction{
+					Trigger:   line.CompactMetadata.Trigger,
+					PreTokens: line.CompactMetadata.PreTokens,
+				})
+			}
+
 		case "user":
+			if line.IsCompactSummary {
+				text, _ := extractClaudeUserContent(line.Message.Content)
+				messages = addCompactionSummary(messages, &idx, text)
+				continue
+			}
+			for _, r := range claudeToolResults(line.Message.Content) {
+				calls.setResult(messages, r.ToolUseID, r.text(), r.IsError)
+			}
 			text, isToolResult := extractClaudeUserContent(line.Message.Content)
 			if isToolResult || text == "" {
 				continue
 			}
+			if resumable && len(messages) >= streamBatchSize {
+				if !emit(messages, &model.Cursor{Offset: offset, Index: idx}) {
+					return
+				}
+				messages, calls = nil, make(toolCallIndex)
+			}
 			messages = append(messages, model.Message{
 				Role:  "user",
 				Text:  text,
 				Index: idx,
+				Fork:  pendingFork,
 			})
+			pendingFork = nil
 			idx++
 
 		case "assistant":
-			text, tools := extractClaudeAssistantContent(line.Message.Content)
-			if text == "" && len(tools) == 0 {
+			usage := pendingUsage.Add(usageSeen.delta(line.Message.ID, line.Message.Usage.toModel()))
+			text, thinking, tools := extractClaudeAssistantContent(line.Message.Content)
+			if thinking != "" {
+				messages = appendThinking(messages, &idx, thinking)
+			}
+			if text == "" && len(tools) == 0 && thinking == "" {
+				pendingUsage = usage
 				continue
 			}
+			pendingUsage = model.Usage{}
+			for i := range tools {
+				tools[i].Subagent = outline.subagents[tools[i].ID]
+			}
 			// merge with previous assistant message if exists
 			if len(messages) > 0 && messages[len(messages)-1].Role == "assistant" {
 				prev := &messages[len(messages)-1]
@@ -77,31 +165,30 @@ func parseClaudeMessages(filePath string) []model.Message {
 					}
 				}
 				prev.ToolCalls = append(prev.ToolCalls, tools...)
-				continue
+				prev.Usage = prev.Usage.Add(usage)
+			} else {
+				messages = append(messages, model.Message{
+					Role:      "assistant",
+					Text:      text,
+					ToolCalls: tools,
+					Index:     idx,
+					Usage:     usage,
+				})
+				idx++
+			}
+			for i := range tools {
+				last := &messages[len(messages)-1]
+				calls[tools[i].ID] = toolCallPos{len(messages) - 1, len(last.ToolCalls) - len(tools) + i}
+			}
+			if pendingFork != nil {
+				messages[len(messages)-1].Fork = pendingFork
+				pendingFork = nil
 			}
-			messages = append(messages, model.Message{
-				Role:      "assistant",
-				Text:      text,
-				ToolCalls: tools,
-				Index:     idx,
-			})
-			idx++
-		}
-	}
-
-	if err := sc.Err(); err != nil {
-		hint := "(parse error: some messages may be missing)"
-		if errors.Is(err, bufio.ErrTooLong) {
-			hint = "(parse stopped: encountered an oversized line)"
 		}
-		messages = append(messages, model.Message{
-			Role:  "assistant",
-			Text:  hint,
-			Index: idx,
-		})
 	}
 
-	return messages
+	messages = skipped.flush(messages, &idx)
+	emit(messages, nil)
 }
 
 // extractClaudeUserContent returns the text and whether this is a tool_result.
@@ -152,20 +239,22 @@ func isClaudeSystemContent(text string) bool {
 		strings.HasPrefix(text, "<environment_context>")
 }
 
-// extractClaudeAssistantContent extracts text and tool calls from assistant content blocks.
-func extractClaudeAssistantContent(raw json.RawMessage) (string, []string) {
+// extractClaudeAssistantContent extracts text, thinking and tool calls from assistant content blocks.
+func extractClaudeAssistantContent(raw json.RawMessage) (string, string, []model.ToolCall) {
 	var blocks []struct {
-		Type  string          `json:"type"`
-		Text  string          `json:"text"`
-		Name  string          `json:"name"`
-		Input json.RawMessage `json:"input"`
+		Type     string          `json:"type"`
+		Text     string          `json:"text"`
+		Thinking string          `json:"thinking"`
+		ID       string          `json:"id"`
+		Name     string          `json:"name"`
+		Input    json.RawMessage `json:"input"`
 	}
 	if err := json.Unmarshal(raw, &blocks); err != nil {
-		return "", nil
+		return "", "", nil
 	}
 
-	var texts []string
-	var tools []string
+	var texts, thinking []string
+	var tools []model.ToolCall
 
 	for _, b := range blocks {
 		switch b.Type {
@@ -174,13 +263,20 @@ func extractClaudeAssistantContent(raw json.RawMessage) (string, []string) {
 				texts = append(texts, b.Text)
 			}
 		case "tool_use":
-			summary := formatToolCall(b.Name, b.Input)
-			tools = append(tools, summary)
-		// skip "thinking" blocks
+			tools = append(tools, model.ToolCall{
+				ID:      b.ID,
+				Name:    b.Name,
+				Summary: formatToolCall(b.Name, b.Input),
+				Input:   string(b.Input),
+			})
+		case "thinking":
+			if b.Thinking != "" {
+				thinking = append(thinking, b.Thinking)
+			}
 		}
 	}
 
-	return strings.Join(texts, "\n"), tools
+	return strings.Join(texts, "\n"), strings.Join(thinking, "\n\n"), tools
 }
 
 // formatToolCall creates a short summary of a tool call.
@@ -199,9 +295,9 @@ func formatToolCall(name string, input json.RawMessage) string {
 		if fp, ok := params["file_path"].(string); ok {
 			return fmt.Sprintf("Write: %s", shortPath(fp))
 		}
-	case "Edit":
+	case "Edit", "MultiEdit":
 		if fp, ok := params["file_path"].(string); ok {
-			return fmt.Sprintf("Edit: %s", shortPath(fp))
+			return fmt.Sprintf("%s: %s", name, shortPath(fp))
 		}
 	case "Glob":
 		if p, ok := params["pattern"].(string); ok {
@@ -255,20 +351,49 @@ func truncateStr(s string, maxLen int) string {
 	return string(runes[:maxLen-2]) + ".."
 }
 
-func parseCodexMessages(filePath string) []model.Message {
+// ParseCodexMessages reads a Codex session JSONL file and returns parsed conversation messages.
+func ParseCodexMessages(filePath string) []model.Message {
+	var messages []model.Message
+	StreamCodexMessages(filePath, model.Cursor{}, collectMessages(&messages))
+	return messages
+}
+
+// StreamCodexMessages reads a Codex conversation from the cursor from the way
+// StreamClaudeMessages does.
+func StreamCodexMessages(filePath string, from model.Cursor, emit func(messages []model.Message, next *model.Cursor) bool) {
 	f, err := os.Open(filePath)
 	if err != nil {
-		return nil
+		emit(nil, nil)
+		return
 	}
 	defer f.Close()
-
-	sc := bufio.NewScanner(f)
-	sc.Buffer(make([]byte, 0, 256*1024), 10*1024*1024) // 10MB to handle large tool outputs
+	if _, err := f.Seek(from.Offset, io.SeekStart); err != nil {
+		emit(nil, nil)
+		return
+	}
+	lr := newLineReader(f, from.Offset, maxLineSize)
 
 	var messages []model.Message
-	idx := 0
+	idx := from.Index
+	usage := codexUsageTracker{total: from.Tokens}
+	var pendingUsage model.Usage // tokens reported before the reply they belong to
+	calls := make(toolCallIndex)
+	var skipped skippedLines
+
+	for {
+		raw, offset, size, err := lr.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			messages = appendNote(messages, &idx, "(parse error: some messages may be missing)")
+			break
+		}
+		if size > 0 {
+			skipped.add(size)
+			continue
+		}
 
-	for sc.Scan() {
 		var line struct {
 			Type    string `json:"type"`
 			Payload struct {
@@ -280,11 +405,86 @@ func parseCodexMessages(filePath string) []model.Message {
 				} `json:"content"`
 			} `json:"payload"`
 		}
-		if err := json.Unmarshal(sc.Bytes(), &line); err != nil {
+		if err := json.Unmarshal(raw, &line); err != nil {
+			continue
+		}
+		// nothing carries over from earlier lines, so reading can resume here
+		resumable := pendingUsage.Total() == 0 && skipped.lines == 0
+		messages = skipped.flush(messages, &idx)
+
+		if line.Type == "event_msg" || line.Type == "turn_context" {
+			var event struct {
+				Payload json.RawMessage `json:"payload"`
+			}
+			json.Unmarshal(raw, &event)
+			// token counts follow the reply they were spent on
+			if u := usage.observe(line.Type, event.Payload); u.Total() != 0 {
+				if len(messages) > 0 && messages[len(messages)-1].Role == "assistant" {
+					last := &messages[len(messages)-1]
+					last.Usage = last.Usage.Add(u)
+				} else {
+					pendingUsage = pendingUsage.Add(u)
+				}
+			}
+			continue
+		}
+
+		if line.Type == "compacted" {
+			var compacted struct {
+				Payload struct {
+					Message string `json:"message"`
+				} `json:"payload"`
+			}
+			json.Unmarshal(raw, &compacted)
+			messages = appendCompaction(messages, &idx, model.Compaction{Summary: strings.TrimSpace(compacted.Payload.Message)})
+			continue
+		}
+
+		if line.Type != "response_item" {
+			continue
+		}
+
+		if isCodexToolCallType(line.Payload.Type) {
+			var call codexToolCall
+			var item struct {
+				Payload json.RawMessage `json:"payload"`
+			}
+			if json.Unmarshal(raw, &item) != nil || json.Unmarshal(item.Payload, &call) != nil {
+				continue
+			}
+			tc := model.ToolCall{ID: call.CallID, Name: call.Name, Summary: call.format(), Input: call.Arguments + call.Input}
+			if call.Type == "local_shell_call" {
+				tc.Name = "local_shell"
+				tc.Input = strings.Join(call.Action.Command, " ")
+			}
+			messages = appendMessage(messages, &idx, "assistant", "", []model.ToolCall{tc}, pendingUsage)
+			calls.addLast(messages)
+			pendingUsage = model.Usage{}
 			continue
 		}
 
-		if line.Type != "response_item" || line.Payload.Type != "message" {
+		if line.Payload.Type == "reasoning" {
+			if thinking := codexReasoning(raw); thinking != "" {
+				messages = appendThinking(messages, &idx, thinking)
+			}
+			continue
+		}
+
+		if strings.HasSuffix(line.Payload.Type, "_call_output") {
+			var out struct {
+				Payload struct {
+					CallID string `json:"call_id"`
+					Output string `json:"output"`
+				} `json:"payload"`
+			}
+			if json.Unmarshal(raw, &out) == nil {
+				text, isError := codexToolOutput(out.Payload.Output)
+				calls.setResult(messages, out.Payload.CallID, text, isError)
+			}
+			continue
+		}
+
+		if line.Payload.Type != "message" {
 			continue
 		}
 
@@ -317,31 +517,70 @@ func parseCodexMessages(filePath string) []model.Message {
 		// merge consecutive assistant messages
 		if role == "assistant" && len(messages) > 0 && messages[len(messages)-1].Role == "assistant" {
 			prev := &messages[len(messages)-1]
-			prev.Text += "\n" + text
+			if prev.Text != "" {
+				prev.Text += "\n" + text
+			} else {
+				prev.Text = text
+			}
 			continue
 		}
 
-		messages = append(messages, model.Message{
+		if role == "user" && resumable && len(messages) >= streamBatchSize {
+			next := &model.Cursor{Offset: offset, Index: idx, Tokens: usage.total}
+			if !emit(messages, next) {
+				return
+			}
+			messages, calls = nil, make(toolCallIndex)
+		}
+
+		msg := model.Message{
 			Role:  role,
 			Text:  text,
 			Index: idx,
-		})
+		}
+		if role == "assistant" {
+			msg.Usage = pendingUsage
+			pendingUsage = model.Usage{}
+		}
+		messages = append(messages, msg)
 		idx++
 	}
 
-	if err := sc.Err(); err != nil {
-		hint := "(parse error: some messages may be missing)"
-		if errors.Is(err, bufio.ErrTooLong) {
-			hint = "(parse stopped: encountered an oversized line)"
+	messages = skipped.flush(messages, &idx)
+	emit(messages, nil)
+}
+
+// codexReasoning returns the reasoning of a "reasoning" response item: the
+// full text when the rollout recorded it, otherwise the summaries shown
+// while the model was thinking.
+func codexReasoning(raw []byte) string {
+	var line struct {
+		Payload struct {
+			Summary []struct {
+				Text string `json:"text"`
+			} `json:"summary"`
+			Content []struct {
+				Text string `json:"text"`
+			} `json:"content"`
+		} `json:"payload"`
+	}
+	if json.Unmarshal(raw, &line) != nil {
+		return ""
+	}
+	var texts []string
+	for _, c := range line.Payload.Content {
+		if c.Text != "" {
+			texts = append(texts, c.Text)
 		}
-		messages = append(messages, model.Message{
-			Role:  "assistant",
-			Text:  hint,
-			Index: idx,
-		})
 	}
-
-	return messages
+	if len(texts) == 0 {
+		for _, s := range line.Payload.Summary {
+			if s.Text != "" {
+				texts = append(texts, s.Text)
+			}
+		}
+	}
+	return strings.Join(texts, "\n\n")
 }
 
 func isCodexSystemMessage(text string) bool {
@@ -0,0 +1,397 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/jackwu/vibesession/model"
+)
+
+// The session header only needs the first few lines of a transcript, but
+// totals such as token usage need the whole file. These stats passes read
+// every line once at scan time; the session index makes sure that only
+// happens again when the file changes.
+
+// sessionStats holds the totals gathered by a stats pass.
+type sessionStats struct {
+	usage       map[string]model.Usage // per model name
+	model       string                 // the most recently used model
+	messages    int                    // user prompts and assistant replies
+	compactions int                    // context compactions
+	prompt      string                 // the first meaningful user prompt
+	title       string                 // the agent's own title for the session
+	lastRole    string
+	tools       []string // distinct tool labels, in order of first use
+	files       []string // distinct files named in tool inputs
+	seen        map[string]bool
+}
+
+// maxSessionFiles bounds the files recorded per session.
+const maxSessionFiles = 200
+
+// patchFileRe matches the file headers of an apply_patch envelope. It stops
+// at a backslash so it also works on JSON-escaped patches.
+var patchFileRe = regexp.MustCompile(`\*\*\* (?:Add|Update|Delete) File: ([^\n"\\]+)`)
+
+// addTool records a tool by its display label ("Edit", "Shell", ...).
+func (st *sessionStats) addTool(label string) {
+	if label == "" || st.seen["tool:"+label] {
+		return
+	}
+	st.mark("tool:" + label)
+	st.tools = append(st.tools, label)
+}
+
+func (st *sessionStats) addFile(path string) {
+	path = strings.TrimSpace(path)
+	if path == "" || len(st.files) >= maxSessionFiles || st.seen["file:"+path] {
+		return
+	}
+	st.mark("file:" + path)
+	st.files = append(st.files, path)
+}
+
+func (st *sessionStats) mark(key string) {
+	if st.seen == nil {
+		st.seen = make(map[string]bool)
+	}
+	st.seen[key] = true
+}
+
+// addToolFiles records the files named by a tool's JSON input, including
+// those of an apply_patch embedded in it.
+func (st *sessionStats) addToolFiles(input []byte) {
+	var params map[string]interface{}
+	if json.Unmarshal(input, &params) == nil {
+		for _, k := range []string{"file_path", "absolute_path", "notebook_path"} {
+			if p, ok := params[k].(string); ok {
+				st.addFile(p)
+			}
+		}
+	}
+	st.addPatchFiles(string(input))
+}
+
+func (st *sessionStats) addPatchFiles(patch string) {
+	for _, m := range patchFileRe.FindAllStringSubmatch(patch, -1) {
+		st.addFile(m[1])
+	}
+}
+
+// addMessage counts a message the way the conversation viewer shows them:
+// consecutive assistant lines are merged into one reply.
+func (st *sessionStats) addMessage(role string) {
+	if role == "assistant" && st.lastRole == "assistant" {
+		return
+	}
+	st.messages++
+	st.lastRole = role
+}
+
+func (st *sessionStats) addUsage(modelName string, u model.Usage) {
+	if u.Total() == 0 {
+		return
+	}
+	if st.usage == nil {
+		st.usage = make(map[string]model.Usage)
+	}
+	st.usage[modelName] = st.usage[modelName].Add(u)
+}
+
+// claudeUsage is the usage block of a Claude assistant message.
+type claudeUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+}
+
+func (u *claudeUsage) toModel() model.Usage {
+	if u == nil {
+		return model.Usage{}
+	}
+	return model.Usage{
+		Input:         u.InputTokens,
+		Output:        u.OutputTokens,
+		CacheRead:     u.CacheReadInputTokens,
+		CacheCreation: u.CacheCreationInputTokens,
+	}
+}
+
+// claudeUsageTracker de-duplicates usage across transcript lines. Claude
+// writes one line per content block of an assistant message, each repeating
+// the message's usage, so only the change since the last line of the same
+// message ID is counted.
+type claudeUsageTracker map[string]model.Usage
+
+func (t claudeUsageTracker) delta(messageID string, u model.Usage) model.Usage {
+	if messageID == "" {
+		return u
+	}
+	prev := t[messageID]
+	t[messageID] = u
+	return u.Sub(prev)
+}
+
+func scanClaudeStats(filePath string) sessionStats {
+	var st sessionStats
+	f, err := os.Open(filePath)
+	if err != nil {
+		return st
+	}
+	defer f.Close()
+
+	seen := make(claudeUsageTracker)
+	usageKey := []byte(`"usage"`)
+	userKey := []byte(`"type":"user"`)
+	compactKey := []byte(`"compact_boundary"`)
+	summaryKey := []byte(`"type":"summary"`)
+	var titles claudeTitles
+	// the message count at the last compact_boundary: the summary that
+	// follows belongs to it, while one without a boundary is counted alone,
+	// matching the dividers addCompactionSummary draws
+	boundaryAt := -1
+
+	eachLine(f, maxLineSize, func(raw []byte) bool {
+		titles.addUUIDs(raw)
+		if !bytes.Contains(raw, usageKey) && !bytes.Contains(raw, userKey) &&
+			!bytes.Contains(raw, compactKey) && !bytes.Contains(raw, summaryKey) {
+			return true
+		}
+		var line struct {
+			Type             string `json:"type"`
+			Subtype          string `json:"subtype"`
+			Summary          string `json:"summary"`
+			LeafUUID         string `json:"leafUuid"`
+			IsCompactSummary bool   `json:"isCompactSummary"`
+			Message          struct {
+				ID      string          `json:"id"`
+				Model   string          `json:"model"`
+				Content json.RawMessage `json:"content"`
+				Usage   *claudeUsage    `json:"usage"`
+			} `json:"message"`
+		}
+		if err := json.Unmarshal(raw, &line); err != nil {
+			return true
+		}
+		if line.Type == "summary" {
+			if line.Summary != "" {
+				titles.summaries = append(titles.summaries, claudeSummary{line.Summary, line.LeafUUID})
+			}
+			return true
+		}
+		if line.Type == "system" && line.Subtype == "compact_boundary" {
+			st.compactions++
+			boundaryAt = st.messages
+			return true
+		}
+		if line.Type == "user" && line.IsCompactSummary {
+			if boundaryAt != st.messages {
+				st.compactions++
+			}
+			boundaryAt = -1
+			return true
+		}
+		if line.Type == "user" {
+			if text, isToolResult := extractClaudeUserContent(line.Message.Content); !isToolResult && text != "" {
+				st.addMessage("user")
+				if st.prompt == "" {
+					if p := cleanClaudePrompt(text); meaningfulPrompt(p) {
+						st.prompt = p
+					}
+				}
+			}
+			return true
+		}
+		if line.Type != "assistant" {
+			return true
+		}
+		st.addMessage("assistant")
+		var blocks []struct {
+			Type  string          `json:"type"`
+			Name  string          `json:"name"`
+			Input json.RawMessage `json:"input"`
+		}
+		if json.Unmarshal(line.Message.Content, &blocks) == nil {
+			for _, b := range blocks {
+				if b.Type == "tool_use" {
+					st.addTool(b.Name)
+					st.addToolFiles(b.Input)
+				}
+			}
+		}
+		st.addUsage(line.Message.Model, seen.delta(line.Message.ID, line.Message.Usage.toModel()))
+		// "<synthetic>" marks messages generated locally, e.g. API errors
+		if line.Message.Model != "" && line.Message.Model != "<synthetic>" {
+			st.model = line.Message.Model
+		}
+		return true
+	})
+	st.title = titles.title()
+	return st
+}
+
+// codexTokenUsage is the token_usage object of a Codex token_count event.
+// Input tokens include the cached ones.
+type codexTokenUsage struct {
+	InputTokens       int `json:"input_tokens"`
+	CachedInputTokens int `json:"cached_input_tokens"`
+	OutputTokens      int `json:"output_tokens"`
+}
+
+func (u codexTokenUsage) toModel() model.Usage {
+	return model.Usage{
+		Input:     u.InputTokens - u.CachedInputTokens,
+		Output:    u.OutputTokens,
+		CacheRead: u.CachedInputTokens,
+	}
+}
+
+// codexUsageTracker turns the cumulative totals of token_count events into
+// increments, so each increment can be charged to the model active at the time.
+type codexUsageTracker struct {
+	model string
+	total model.Usage
+}
+
+// observe handles a rollout line and returns the tokens it added, if any.
+func (t *codexUsageTracker) observe(lineType string, payload json.RawMessage) model.Usage {
+	switch lineType {
+	case "turn_context":
+		var tc struct {
+			Model string `json:"model"`
+		}
+		if json.Unmarshal(payload, &tc) == nil && tc.Model != "" {
+			t.model = tc.Model
+		}
+	case "event_msg":
+		var ev struct {
+			Type string `json:"type"`
+			Info *struct {
+				TotalTokenUsage codexTokenUsage `json:"total_token_usage"`
+			} `json:"info"`
+		}
+		if json.Unmarshal(payload, &ev) != nil || ev.Type != "token_count" || ev.Info == nil {
+			return model.Usage{}
+		}
+		total := ev.Info.TotalTokenUsage.toModel()
+		delta := total.Sub(t.total)
+		t.total = total
+		if delta.Total() < 0 {
+			// totals reset (e.g. a resumed session); count the new total
+			return total
+		}
+		return delta
+	}
+	return model.Usage{}
+}
+
+// codexMessageRole returns the role of a response_item message that the
+// conversation viewer would show, or "" for anything else.
+func codexMessageRole(payload json.RawMessage) string {
+	var item struct {
+		Type    string `json:"type"`
+		Role    string `json:"role"`
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	if json.Unmarshal(payload, &item) != nil || item.Type != "message" {
+		return ""
+	}
+	if item.Role != "user" && item.Role != "assistant" {
+		return ""
+	}
+	for _, c := range item.Content {
+		if c.Text != "" && (item.Role == "assistant" || !isCodexSystemMessage(c.Text)) {
+			return item.Role
+		}
+	}
+	return ""
+}
+
+var codexToolCallKeys = [][]byte{
+	[]byte(`"type":"function_call"`),
+	[]byte(`"type":"custom_tool_call"`),
+	[]byte(`"type":"local_shell_call"`),
+}
+
+func isCodexToolCallLine(raw []byte) bool {
+	for _, k := range codexToolCallKeys {
+		if bytes.Contains(raw, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// addCodexToolCall records a function_call, custom_tool_call or
+// local_shell_call response item.
+func (st *sessionStats) addCodexToolCall(payload json.RawMessage) {
+	var call codexToolCall
+	if json.Unmarshal(payload, &call) != nil {
+		return
+	}
+	// the viewer shows tool calls as (part of) an assistant reply
+	st.addMessage("assistant")
+	label, _, _ := strings.Cut(call.format(), ":")
+	st.addTool(label)
+	st.addToolFiles([]byte(call.Arguments))
+	st.addPatchFiles(call.Input)
+}
+
+func scanCodexStats(filePath string) sessionStats {
+	var st sessionStats
+	f, err := os.Open(filePath)
+	if err != nil {
+		return st
+	}
+	defer f.Close()
+
+	var tracker codexUsageTracker
+	var early model.Usage // counted before the first turn_context named a model
+	eachLine(f, maxLineSize, func(raw []byte) bool {
+		// tool outputs dominate the file; only decode the lines we need
+		if !bytes.Contains(raw, []byte(`"token_count"`)) && !bytes.Contains(raw, []byte(`"turn_context"`)) &&
+			!bytes.Contains(raw, []byte(`"type":"message"`)) && !bytes.Contains(raw, []byte(`"type":"compacted"`)) &&
+			!isCodexToolCallLine(raw) {
+			return true
+		}
+		var line struct {
+			Type    string          `json:"type"`
+			Payload json.RawMessage `json:"payload"`
+		}
+		if err := json.Unmarshal(raw, &line); err != nil {
+			return true
+		}
+		if line.Type == "compacted" {
+			st.compactions++
+			return true
+		}
+		if line.Type == "response_item" {
+			if role := codexMessageRole(line.Payload); role != "" {
+				st.addMessage(role)
+			} else if isCodexToolCallLine(raw) {
+				st.addCodexToolCall(line.Payload)
+			}
+			return true
+		}
+		u := tracker.observe(line.Type, line.Payload)
+		if tracker.model == "" {
+			early = early.Add(u)
+			return true
+		}
+		if early.Total() != 0 {
+			st.addUsage(tracker.model, early)
+			early = model.Usage{}
+		}
+		st.addUsage(tracker.model, u)
+		return true
+	})
+	st.addUsage(tracker.model, early)
+	st.model = tracker.model
+	return st
+}
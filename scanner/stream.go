@@ -0,0 +1,66 @@
+package scanner
+
+import (
+	"fmt"
+
+	"github.com/jackwu/vibesession/model"
+)
+
+// streamBatchSize is how many messages the streaming parsers collect before
+// handing them on, at the next point reading could resume from.
+const streamBatchSize = 50
+
+// collectMessages returns an emit function for the streaming parsers that
+// appends everything to *messages.
+func collectMessages(messages *[]model.Message) func([]model.Message, *model.Cursor) bool {
+	return func(batch []model.Message, _ *model.Cursor) bool {
+		*messages = append(*messages, batch...)
+		return true
+	}
+}
+
+// appendNote adds a message from vbs itself, such as a warning that part of
+// the transcript could not be read.
+func appendNote(messages []model.Message, idx *int, text string) []model.Message {
+	messages = append(messages, model.Message{Role: "system", Text: text, Index: *idx})
+	*idx++
+	return messages
+}
+
+// skippedLines tallies transcript lines too long to read, so a run of them
+// is reported once, where they were.
+type skippedLines struct {
+	lines int
+	size  int64
+}
+
+func (s *skippedLines) add(size int64) {
+	s.lines++
+	s.size += size
+}
+
+// flush notes the lines skipped since the last call, if any.
+func (s *skippedLines) flush(messages []model.Message, idx *int) []model.Message {
+	if s.lines == 0 {
+		return messages
+	}
+	text := fmt.Sprintf("(skipped a %s line too long to show)", formatSize(s.size))
+	if s.lines > 1 {
+		text = fmt.Sprintf("(skipped %d lines too long to show, %s)", s.lines, formatSize(s.size))
+	}
+	*s = skippedLines{}
+	return appendNote(messages, idx, text)
+}
+
+// formatSize formats a byte count like "12.5 MB".
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
@@ -0,0 +1,179 @@
+package scanner
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/jackwu/vibesession/model"
+)
+
+// claudeTaskCall is a Task tool call, which spawns a subagent.
+type claudeTaskCall struct {
+	id     string // tool_use id
+	prompt string // the prompt handed to the subagent
+}
+
+// toolResultAgentID returns the agentId recorded in a Task tool result, if any.
+func toolResultAgentID(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var result struct {
+		AgentID string `json:"agentId"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return ""
+	}
+	return result.AgentID
+}
+
+// claudeTaskCalls returns the Task calls of an assistant line.
+func claudeTaskCalls(raw []byte) []claudeTaskCall {
+	var line struct {
+		Message struct {
+			Content []struct {
+				Type  string `json:"type"`
+				ID    string `json:"id"`
+				Name  string `json:"name"`
+				Input struct {
+					Prompt string `json:"prompt"`
+				} `json:"input"`
+			} `json:"content"`
+		} `json:"message"`
+	}
+	if json.Unmarshal(raw, &line) != nil {
+		return nil
+	}
+	var tasks []claudeTaskCall
+	for _, b := range line.Message.Content {
+		if b.Type == "tool_use" && b.Name == "Task" {
+			tasks = append(tasks, claudeTaskCall{id: b.ID, prompt: b.Input.Prompt})
+		}
+	}
+	return tasks
+}
+
+// claudeTaskAgents records the agentId a user line reports for the Task
+// calls it has results for.
+func claudeTaskAgents(raw []byte, taskAgents map[string]string) {
+	var line struct {
+		Message struct {
+			Content json.RawMessage `json:"content"`
+		} `json:"message"`
+		ToolUseResult json.RawMessage `json:"toolUseResult"`
+	}
+	if json.Unmarshal(raw, &line) != nil {
+		return
+	}
+	agentID := toolResultAgentID(line.ToolUseResult)
+	if agentID == "" {
+		return
+	}
+	for _, r := range claudeToolResults(line.Message.Content) {
+		taskAgents[r.ToolUseID] = agentID
+	}
+}
+
+// matchSubagents pairs each Task call with the subagent transcript it
+// spawned and returns the transcripts by tool_use id. Calls are matched by
+// the agentId in their result, then by identical prompt. Any left over are
+// paired with the remaining subagents in order only if there are as many of
+// each: a call that failed before spawning one would shift the rest.
+func matchSubagents(tasks []claudeTaskCall, taskAgents map[string]string, subagents []model.Session) map[string]string {
+	if len(tasks) == 0 || len(subagents) == 0 {
+		return nil
+	}
+
+	used := make([]bool, len(subagents))
+	matched := make([]int, len(tasks))
+	for i := range matched {
+		matched[i] = -1
+	}
+	claim := func(t int, match func(s model.Session) bool) {
+		for i, s := range subagents {
+			if !used[i] && match(s) {
+				used[i] = true
+				matched[t] = i
+				return
+			}
+		}
+	}
+
+	for t, tc := range tasks {
+		if agentID := taskAgents[tc.id]; agentID != "" {
+			claim(t, func(s model.Session) bool { return s.AgentID == agentID })
+		}
+	}
+
+	var prompts []string // first prompt of each subagent, read lazily
+	for t, tc := range tasks {
+		if matched[t] >= 0 || tc.prompt == "" {
+			continue
+		}
+		if prompts == nil {
+			prompts = make([]string, len(subagents))
+			for i, s := range subagents {
+				prompts[i] = firstClaudePrompt(s.FilePath)
+			}
+		}
+		for i := range subagents {
+			if !used[i] && prompts[i] == tc.prompt {
+				used[i] = true
+				matched[t] = i
+				break
+			}
+		}
+	}
+
+	leftTasks, leftAgents := 0, 0
+	for t := range tasks {
+		if matched[t] < 0 {
+			leftTasks++
+		}
+	}
+	for i := range subagents {
+		if !used[i] {
+			leftAgents++
+		}
+	}
+	if leftTasks == leftAgents {
+		for t := range tasks {
+			if matched[t] < 0 {
+				claim(t, func(model.Session) bool { return true })
+			}
+		}
+	}
+
+	links := make(map[string]string)
+	for t, tc := range tasks {
+		if matched[t] >= 0 {
+			links[tc.id] = subagents[matched[t]].FilePath
+		}
+	}
+	return links
+}
+
+// firstClaudePrompt returns the full text of the first user message in a transcript.
+func firstClaudePrompt(filePath string) string {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	prompt, lines := "", 0
+	eachLine(f, maxLineSize, func(raw []byte) bool {
+		lines++
+		var line struct {
+			Type    string `json:"type"`
+			Message struct {
+				Content json.RawMessage `json:"content"`
+			} `json:"message"`
+		}
+		if err := json.Unmarshal(raw, &line); err == nil && line.Type == "user" {
+			prompt, _ = extractClaudeUserContent(line.Message.Content)
+		}
+		return prompt == "" && lines < 10
+	})
+	return prompt
+}
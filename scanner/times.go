@@ -0,0 +1,93 @@
+package scanner
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"time"
+)
+
+// Claude and Codex stamp every transcript line with an RFC 3339 "timestamp".
+// The first and last ones bound the session, so only the head and the tail
+// of the file need to be read to find them.
+
+const (
+	timeHeadLines = 20
+	timeTailBytes = 64 * 1024
+	timeTailMax   = 4 * 1024 * 1024 // give up on lines longer than this
+)
+
+var timestampKey = []byte(`"timestamp":"`)
+
+// lineTimestamp returns the first "timestamp" value in a JSON line without
+// decoding the whole line.
+func lineTimestamp(line []byte) time.Time {
+	i := bytes.Index(line, timestampKey)
+	if i < 0 {
+		return time.Time{}
+	}
+	rest := line[i+len(timestampKey):]
+	end := bytes.IndexByte(rest, '"')
+	if end < 0 {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339Nano, string(rest[:end]))
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// transcriptTimeSpan returns the first and last line timestamps of a JSONL
+// transcript. Either is zero if none was found.
+func transcriptTimeSpan(filePath string) (start, end time.Time) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	n := 0
+	eachLine(f, timeTailMax, func(line []byte) bool {
+		start = lineTimestamp(line)
+		n++
+		return start.IsZero() && n < timeHeadLines
+	})
+
+	info, err := f.Stat()
+	if err != nil {
+		return
+	}
+	lines := tailLines(f, info.Size())
+	for i := len(lines) - 1; i >= 0; i-- {
+		if end = lineTimestamp(lines[i]); !end.IsZero() {
+			break
+		}
+	}
+	return
+}
+
+// tailLines returns the complete lines at the end of f, reading backwards
+// from size until at least one whole line is found.
+func tailLines(f *os.File, size int64) [][]byte {
+	for n := int64(timeTailBytes); ; n *= 2 {
+		off := max(0, size-n)
+		buf := make([]byte, size-off)
+		if _, err := f.ReadAt(buf, off); err != nil && err != io.EOF {
+			return nil
+		}
+		// the first line is partial unless reading started at the beginning
+		if off > 0 {
+			i := bytes.IndexByte(buf, '\n')
+			if i < 0 || i == len(buf)-1 {
+				if n >= timeTailMax {
+					return nil
+				}
+				continue
+			}
+			buf = buf[i+1:]
+		}
+
+		return bytes.Split(bytes.TrimRight(buf, "\r\n"), []byte("\n"))
+	}
+}
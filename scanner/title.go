@@ -0,0 +1,100 @@
+package scanner
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+// boilerplatePrompts say nothing about what a session is about, so they
+// never make its summary.
+var boilerplatePrompts = map[string]bool{
+	"continue":   true,
+	"go on":      true,
+	"go ahead":   true,
+	"keep going": true,
+	"proceed":    true,
+	"yes":        true,
+	"y":          true,
+	"ok":         true,
+	"okay":       true,
+	"thanks":     true,
+	"thank you":  true,
+	"retry":      true,
+	"try again":  true,
+	"hi":         true,
+	"hello":      true,
+}
+
+var xmlTagRe = regexp.MustCompile(`<[^>]+>`)
+
+// cleanClaudePrompt strips the tags Claude Code wraps prompts in. For
+// teammate sessions it keeps the task instead of the role boilerplate.
+func cleanClaudePrompt(text string) string {
+	text = teammateTagRe.ReplaceAllString(text, "")
+	text = teammateCloseRe.ReplaceAllString(text, "")
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "You are") {
+		if idx := strings.Index(text, "Your task is"); idx >= 0 {
+			text = text[idx:]
+		}
+	}
+	return strings.TrimSpace(xmlTagRe.ReplaceAllString(text, ""))
+}
+
+// meaningfulPrompt reports whether a cleaned prompt describes the work,
+// rather than being a nudge like "continue" or an interruption marker.
+func meaningfulPrompt(text string) bool {
+	if text == "" || strings.HasPrefix(text, "[Request interrupted") {
+		return false
+	}
+	word := strings.ToLower(strings.Trim(text, " .!?\n"))
+	return !boilerplatePrompts[word]
+}
+
+// claudeTitles collects the titles Claude Code records for a conversation:
+//
+//	{"type":"summary","summary":"Fix payment webhook retries","leafUuid":"..."}
+//
+// A summary only belongs to this transcript if its leaf entry is in it;
+// transcripts of resumed sessions also carry the titles of earlier ones.
+type claudeTitles struct {
+	summaries []claudeSummary // in file order
+	uuids     map[string]bool
+}
+
+type claudeSummary struct {
+	title, leaf string
+}
+
+var uuidKey = []byte(`"uuid":"`)
+
+// addUUIDs records the entry uuids on a transcript line without decoding it.
+func (t *claudeTitles) addUUIDs(raw []byte) {
+	for {
+		i := bytes.Index(raw, uuidKey)
+		if i < 0 {
+			return
+		}
+		raw = raw[i+len(uuidKey):]
+		end := bytes.IndexByte(raw, '"')
+		if end < 0 {
+			return
+		}
+		if t.uuids == nil {
+			t.uuids = make(map[string]bool)
+		}
+		t.uuids[string(raw[:end])] = true
+		raw = raw[end:]
+	}
+}
+
+// title returns the latest title whose conversation is in this transcript.
+func (t *claudeTitles) title() string {
+	for i := len(t.summaries) - 1; i >= 0; i-- {
+		if s := t.summaries[i]; t.uuids[s.leaf] {
+			return s.title
+		}
+	}
+	return ""
+}
@@ -0,0 +1,150 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jackwu/vibesession/model"
+)
+
+// Tool results can be whole files or build logs; the viewer only needs
+// enough to see what happened.
+const (
+	maxToolOutputLines = 60
+	maxToolOutputBytes = 8 * 1024
+)
+
+// toolOutputExcerpt trims s to the first lines of output.
+func toolOutputExcerpt(s string) string {
+	s = strings.TrimRight(s, " \t\r\n")
+	cut := false
+	if len(s) > maxToolOutputBytes {
+		s = s[:maxToolOutputBytes]
+		for !utf8.ValidString(s) {
+			s = s[:len(s)-1]
+		}
+		cut = true
+	}
+	lines := strings.Split(s, "\n")
+	if len(lines) > maxToolOutputLines {
+		more := len(lines) - maxToolOutputLines
+		s = strings.Join(lines[:maxToolOutputLines], "\n")
+		return s + fmt.Sprintf("\n… (%d more lines)", more)
+	}
+	if cut {
+		s += "\n… (truncated)"
+	}
+	return s
+}
+
+// toolCallPos locates a tool call within the parsed messages.
+type toolCallPos struct {
+	msg, tool int
+}
+
+// toolCallIndex pairs tool results with the calls they answer.
+type toolCallIndex map[string]toolCallPos
+
+// addLast records the last tool call of the last message under its ID.
+func (ix toolCallIndex) addLast(messages []model.Message) {
+	if len(messages) == 0 {
+		return
+	}
+	mi := len(messages) - 1
+	ti := len(messages[mi].ToolCalls) - 1
+	if ti >= 0 && messages[mi].ToolCalls[ti].ID != "" {
+		ix[messages[mi].ToolCalls[ti].ID] = toolCallPos{mi, ti}
+	}
+}
+
+// setResult stores a result on the call with the given ID, if known.
+func (ix toolCallIndex) setResult(messages []model.Message, id, output string, isError bool) {
+	pos, ok := ix[id]
+	if !ok {
+		return
+	}
+	tc := &messages[pos.msg].ToolCalls[pos.tool]
+	tc.HasResult = true
+	tc.Output = toolOutputExcerpt(output)
+	tc.IsError = isError
+}
+
+// claudeToolResult is a tool_result block of a Claude user message.
+type claudeToolResult struct {
+	ToolUseID string          `json:"tool_use_id"`
+	Content   json.RawMessage `json:"content"`
+	IsError   bool            `json:"is_error"`
+}
+
+// claudeToolResults returns the tool_result blocks in content.
+func claudeToolResults(raw json.RawMessage) []claudeToolResult {
+	var blocks []struct {
+		Type string `json:"type"`
+		claudeToolResult
+	}
+	if err := json.Unmarshal(raw, &blocks); err != nil {
+		return nil
+	}
+	var results []claudeToolResult
+	for _, b := range blocks {
+		if b.Type == "tool_result" && b.ToolUseID != "" {
+			results = append(results, b.claudeToolResult)
+		}
+	}
+	return results
+}
+
+// text returns the result content, which is a string or an array of blocks.
+func (r claudeToolResult) text() string {
+	var str string
+	if err := json.Unmarshal(r.Content, &str); err == nil {
+		return str
+	}
+	var blocks []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(r.Content, &blocks); err != nil {
+		return ""
+	}
+	var texts []string
+	for _, b := range blocks {
+		switch b.Type {
+		case "text":
+			texts = append(texts, b.Text)
+		case "image":
+			texts = append(texts, "[image]")
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+var codexExitCodeRe = regexp.MustCompile(`(?m)^(?:Exit code:|Process exited with code) (-?\d+)`)
+
+// codexToolOutput decodes the output of a Codex tool call. Shell outputs are
+// either JSON ({"output": ..., "metadata": {"exit_code": N}}) or, in newer
+// versions, text starting with "Exit code: N" (exec_command: a few header
+// lines including "Process exited with code N"), then "Output:".
+func codexToolOutput(output string) (text string, isError bool) {
+	var structured struct {
+		Output   *string `json:"output"`
+		Metadata struct {
+			ExitCode int `json:"exit_code"`
+		} `json:"metadata"`
+	}
+	if json.Unmarshal([]byte(output), &structured) == nil && structured.Output != nil {
+		return *structured.Output, structured.Metadata.ExitCode != 0
+	}
+	if m := codexExitCodeRe.FindStringSubmatch(output); m != nil {
+		code, _ := strconv.Atoi(m[1])
+		if _, rest, ok := strings.Cut(output, "\nOutput:\n"); ok {
+			output = rest
+		}
+		return output, code != 0
+	}
+	return output, false
+}
@@ -0,0 +1,90 @@
+package search
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/jackwu/vibesession/model"
+	"github.com/jackwu/vibesession/source"
+)
+
+// snippetWidth is the length of the context returned with each result.
+const snippetWidth = 100
+
+// Result is a matching session with the context of its best hit.
+type Result struct {
+	Session model.Session
+	Message int    // position of the matched message in the parsed conversation
+	Snippet string // matched message text around the hit
+	Term    string // the matched word as it appears in Snippet
+}
+
+// Find searches ix for query among sessions and returns at most limit
+// results, best first, with snippets. Sessions with the same score are
+// ordered by recency.
+func (ix *Index) Find(sessions []model.Session, query string, limit int) []Result {
+	byKey := make(map[string]model.Session, len(sessions))
+	for _, s := range sessions {
+		byKey[Key(s)] = s
+	}
+
+	hits := ix.Search(query)
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return byKey[hits[i].Key].Updated().After(byKey[hits[j].Key].Updated())
+	})
+
+	var results []Result
+	for _, h := range hits {
+		s, ok := byKey[h.Key]
+		if !ok {
+			continue
+		}
+		if len(results) == limit {
+			break
+		}
+		r := Result{Session: s, Message: h.Message}
+		// snippets need the text, which the index doesn't keep
+		if msg, ok := ix.messageAt(h.Key, s, h.Message); ok {
+			text := msg.Text
+			if len(msg.ToolCalls) > 0 {
+				text += " " + strings.Join(toolSummaries(msg), " ")
+			}
+			r.Snippet, r.Term = Snippet(text, query, snippetWidth)
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// messageAt returns message i of the conversation of s, reading from the
+// start of the batch it was indexed in and no further than the message.
+func (ix *Index) messageAt(key string, s model.Session, i int) (model.Message, bool) {
+	var from model.Cursor
+	start := 0
+	ix.mu.Lock()
+	if d := ix.docs[key]; d != nil {
+		for _, p := range d.Pages {
+			if p.Pos > i {
+				break
+			}
+			from, start = p.Cursor, p.Pos
+		}
+	}
+	ix.mu.Unlock()
+	i -= start
+
+	var msg model.Message
+	found := false
+	source.StreamMessages(s, "", from, func(msgs []model.Message, _ *model.Cursor) bool {
+		if i < len(msgs) {
+			msg, found = msgs[i], true
+			return false
+		}
+		i -= len(msgs)
+		return true
+	})
+	return msg, found
+}
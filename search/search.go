@@ -0,0 +1,363 @@
+// Package search maintains a full-text index over the messages of all
+// sessions, so a session can be found by anything said in it.
+package search
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/jackwu/vibesession/model"
+	"github.com/jackwu/vibesession/source"
+)
+
+// indexVersion must be bumped whenever tokenizing or the stored layout
+// changes, so an older index is rebuilt instead of trusted.
+const indexVersion = 3
+
+// maxTermLen caps indexed words; longer runs are hashes, base64 and the like.
+const maxTermLen = 40
+
+// doc is the indexed content of one session.
+type doc struct {
+	FilePath string           `json:"path"`
+	Size     int64            `json:"size"`
+	ModTime  int64            `json:"mtime"` // unix nanoseconds
+	Terms    map[string][]int `json:"terms"` // term -> positions of the messages containing it
+	Pages    []page           `json:"pages"` // where each batch of messages after the first starts
+}
+
+// page records where a batch of messages starts in a transcript, so a
+// message can be read again without reading everything before it.
+type page struct {
+	Pos    int          `json:"pos"` // position of the batch's first message
+	Cursor model.Cursor `json:"cursor"`
+}
+
+type indexFile struct {
+	Version int             `json:"version"`
+	Docs    map[string]*doc `json:"docs"`
+}
+
+// Index maps terms to the sessions and messages they occur in.
+type Index struct {
+	mu    sync.Mutex
+	docs  map[string]*doc             // by session key
+	terms map[string]map[string][]int // term -> session key -> message positions
+	dirty bool
+}
+
+// Hit is a session matching a query.
+type Hit struct {
+	Key     string // session key, see Key
+	Message int    // position of the best matching message
+	Score   int    // number of messages containing every query term
+}
+
+// Key identifies a session in the index. Session IDs can repeat across
+// transcripts, and one file can hold several sessions (Aider, Gemini's
+// logs.json), so it takes both.
+func Key(s model.Session) string {
+	return s.FilePath + "#" + s.ID
+}
+
+func indexPath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".cache", "vbs", "search.json")
+}
+
+// Open loads the index from disk. A missing, corrupt or outdated index
+// yields an empty one that Update fills from scratch.
+func Open() *Index {
+	ix := &Index{docs: make(map[string]*doc)}
+	data, err := os.ReadFile(indexPath())
+	if err == nil {
+		var f indexFile
+		if json.Unmarshal(data, &f) == nil && f.Version == indexVersion && f.Docs != nil {
+			ix.docs = f.Docs
+		}
+	}
+	ix.rebuildTerms()
+	return ix
+}
+
+func (ix *Index) rebuildTerms() {
+	ix.terms = make(map[string]map[string][]int)
+	for key, d := range ix.docs {
+		ix.addTerms(key, d)
+	}
+}
+
+func (ix *Index) addTerms(key string, d *doc) {
+	for term, msgs := range d.Terms {
+		postings := ix.terms[term]
+		if postings == nil {
+			postings = make(map[string][]int)
+			ix.terms[term] = postings
+		}
+		postings[key] = msgs
+	}
+}
+
+// Update re-indexes the sessions whose transcript changed since it was last
+// indexed and drops sessions that no longer exist.
+func (ix *Index) Update(sessions []model.Session) {
+	type job struct {
+		key  string
+		s    model.Session
+		info os.FileInfo
+	}
+	var jobs []job
+	live := make(map[string]bool, len(sessions))
+
+	ix.mu.Lock()
+	for _, s := range sessions {
+		key := Key(s)
+		live[key] = true
+		info, err := os.Stat(s.FilePath)
+		if err != nil {
+			continue
+		}
+		if d, ok := ix.docs[key]; ok && d.FilePath == s.FilePath &&
+			d.Size == info.Size() && d.ModTime == info.ModTime().UnixNano() {
+			continue
+		}
+		jobs = append(jobs, job{key, s, info})
+	}
+	removed := false
+	for key := range ix.docs {
+		if !live[key] {
+			delete(ix.docs, key)
+			removed = true
+		}
+	}
+	ix.mu.Unlock()
+
+	if len(jobs) == 0 && !removed {
+		return
+	}
+
+	// parsing dominates, so spread it over all CPUs
+	ch := make(chan job)
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range ch {
+				d := &doc{
+					FilePath: j.s.FilePath,
+					Size:     j.info.Size(),
+					ModTime:  j.info.ModTime().UnixNano(),
+				}
+				d.Terms, d.Pages = indexMessages(j.s)
+				ix.mu.Lock()
+				ix.docs[j.key] = d
+				ix.mu.Unlock()
+			}
+		}()
+	}
+	for _, j := range jobs {
+		ch <- j
+	}
+	close(ch)
+	wg.Wait()
+
+	ix.mu.Lock()
+	ix.rebuildTerms()
+	ix.dirty = true
+	ix.mu.Unlock()
+}
+
+// indexMessages returns, for each term, the positions of the messages of s
+// it occurs in, and where each batch of messages after the first starts.
+// Tool calls are indexed along with the text.
+func indexMessages(s model.Session) (map[string][]int, []page) {
+	terms := make(map[string][]int)
+	var pages []page
+	i := -1
+	source.StreamMessages(s, "", model.Cursor{}, func(msgs []model.Message, next *model.Cursor) bool {
+		for _, msg := range msgs {
+			i++
+			if msg.IsNote() {
+				continue
+			}
+			text := msg.Text + "\n" + strings.Join(toolSummaries(msg), "\n")
+			for _, t := range Tokenize(text) {
+				if positions := terms[t]; len(positions) == 0 || positions[len(positions)-1] != i {
+					terms[t] = append(positions, i)
+				}
+			}
+		}
+		if next != nil {
+			pages = append(pages, page{Pos: i + 1, Cursor: *next})
+		}
+		return true
+	})
+	return terms, pages
+}
+
+// toolSummaries returns the one-line summaries of msg's tool calls.
+func toolSummaries(msg model.Message) []string {
+	var summaries []string
+	for _, tc := range msg.ToolCalls {
+		summaries = append(summaries, tc.Summary)
+	}
+	return summaries
+}
+
+// Save writes the index to disk if it changed.
+func (ix *Index) Save() error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if !ix.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(indexFile{Version: indexVersion, Docs: ix.docs})
+	if err != nil {
+		return err
+	}
+	path := indexPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// atomic write (tmp -> rename) so a crash never leaves a half-written index
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	ix.dirty = false
+	return nil
+}
+
+// Search returns the sessions containing every term of query, best first.
+// The last query term also matches as a prefix, so results can follow typing.
+func (ix *Index) Search(query string) []Hit {
+	words := Tokenize(query)
+	if len(words) == 0 {
+		return nil
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	// per query word: session key -> set of matching message positions
+	var matches []map[string]map[int]bool
+	for i, w := range words {
+		m := make(map[string]map[int]bool)
+		add := func(postings map[string][]int) {
+			for key, msgs := range postings {
+				set := m[key]
+				if set == nil {
+					set = make(map[int]bool)
+					m[key] = set
+				}
+				for _, mi := range msgs {
+					set[mi] = true
+				}
+			}
+		}
+		if i == len(words)-1 {
+			for term, postings := range ix.terms {
+				if strings.HasPrefix(term, w) {
+					add(postings)
+				}
+			}
+		} else {
+			add(ix.terms[w])
+		}
+		if len(m) == 0 {
+			return nil
+		}
+		matches = append(matches, m)
+	}
+
+	var hits []Hit
+	for key, first := range matches[0] {
+		inAll := true
+		for _, m := range matches[1:] {
+			if m[key] == nil {
+				inAll = false
+				break
+			}
+		}
+		if !inAll {
+			continue
+		}
+
+		// prefer messages that contain every word on their own
+		hit := Hit{Key: key, Message: -1}
+		earliest := -1
+		for mi := range first {
+			if earliest < 0 || mi < earliest {
+				earliest = mi
+			}
+			all := true
+			for _, m := range matches[1:] {
+				if !m[key][mi] {
+					all = false
+					break
+				}
+			}
+			if all {
+				hit.Score++
+				if hit.Message < 0 || mi < hit.Message {
+					hit.Message = mi
+				}
+			}
+		}
+		if hit.Message < 0 {
+			hit.Message = earliest
+		}
+		hits = append(hits, hit)
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Key < hits[j].Key
+	})
+	return hits
+}
+
+// Tokenize splits text into lowercased words. Han, kana and hangul are not
+// separated by spaces, so each of those characters is a word of its own.
+func Tokenize(text string) []string {
+	var words []string
+	var cur []rune
+	flush := func() {
+		if len(cur) >= 2 && len(cur) <= maxTermLen {
+			words = append(words, string(cur))
+		}
+		cur = cur[:0]
+	}
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			flush()
+			words = append(words, string(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			cur = append(cur, unicode.ToLower(r))
+		default:
+			flush()
+		}
+	}
+	flush()
+	return words
+}
+
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r)
+}
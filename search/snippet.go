@@ -0,0 +1,59 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Snippet returns about width runes of text around the first occurrence of
+// a query word, on one line, along with the word as it appears in text.
+// It returns the start of text and "" if no word occurs in it.
+func Snippet(text, query string, width int) (string, string) {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	at, n := -1, 0
+	for _, w := range Tokenize(query) {
+		if i := runeIndex(lower, []rune(w)); i >= 0 && (at < 0 || i < at) {
+			at, n = i, len([]rune(w))
+		}
+	}
+	if at < 0 {
+		return clip(runes, 0, width), ""
+	}
+
+	// show some context before the match, but keep the match in view
+	start := max(0, at-width/4)
+	return clip(runes, start, width), string(runes[at : at+n])
+}
+
+func clip(runes []rune, start, width int) string {
+	end := min(len(runes), start+width)
+	s := string(runes[start:end])
+	if start > 0 {
+		s = "…" + s
+	}
+	if end < len(runes) {
+		s += "…"
+	}
+	return s
+}
+
+func runeIndex(s, sub []rune) int {
+	for i := 0; i+len(sub) <= len(s); i++ {
+		match := true
+		for j := range sub {
+			if s[i+j] != sub[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
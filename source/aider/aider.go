@@ -0,0 +1,91 @@
+// Package aider registers the Aider chat history provider.
+package aider
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/jackwu/vibesession/config"
+	"github.com/jackwu/vibesession/launcher"
+	"github.com/jackwu/vibesession/model"
+	"github.com/jackwu/vibesession/scanner"
+	"github.com/jackwu/vibesession/source"
+)
+
+func init() {
+	source.Register(40, provider{})
+}
+
+type provider struct{}
+
+func (provider) Source() model.Source { return model.SourceAider }
+func (provider) Label() string        { return "Aider" }
+func (provider) Color() string        { return "170" }
+
+// Scan searches only the configured roots; ScanAll uses ScanProjects instead.
+func (p provider) Scan() ([]model.Session, []model.Diagnostic) {
+	return p.ScanProjects(nil)
+}
+
+// ScanProjects searches the configured aider_roots (and their immediate
+// subdirectories) plus the working directory of every known session.
+func (provider) ScanProjects(known []model.Session) ([]model.Session, []model.Diagnostic) {
+	var dirs []string
+	cfg, _ := config.Load()
+	for _, root := range cfg.AiderRoots {
+		root = config.ExpandHome(root)
+		dirs = append(dirs, root)
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if e.IsDir() {
+				dirs = append(dirs, filepath.Join(root, e.Name()))
+			}
+		}
+	}
+	for _, s := range known {
+		if s.CWD != "" {
+			dirs = append(dirs, s.CWD)
+		}
+	}
+	return scanner.ScanAider(dirs)
+}
+
+func (provider) ParseMessages(s model.Session) []model.Message {
+	return scanner.ParseAiderMessages(s.FilePath, s.ID)
+}
+
+// ResumeCommand restores the project's whole chat history file; Aider has
+// no way to pick one chat. A session read from the input history has no
+// chat to restore.
+func (provider) ResumeCommand(s model.Session) string {
+	if promptsOnly(s) {
+		return ""
+	}
+	return launcher.Cd(s.CWD) + " && aider --restore-chat-history"
+}
+
+func (provider) YoloCommand(s model.Session) string {
+	if promptsOnly(s) {
+		return ""
+	}
+	return launcher.Cd(s.CWD) + " && aider --restore-chat-history --yes-always"
+}
+
+func (provider) ResumeNote(s model.Session) string {
+	return "restores every chat in " + filepath.Base(s.FilePath) + ", not just this one"
+}
+
+func (provider) NewCommand(dir string, yolo bool) string {
+	if yolo {
+		return launcher.Cd(dir) + " && aider --yes-always"
+	}
+	return launcher.Cd(dir) + " && aider"
+}
+
+// promptsOnly reports whether s was read from .aider.input.history.
+func promptsOnly(s model.Session) bool {
+	return filepath.Base(s.FilePath) == ".aider.input.history"
+}
@@ -0,0 +1,71 @@
+// Package claude registers the Claude Code session provider.
+package claude
+
+import (
+	"path/filepath"
+
+	"github.com/jackwu/vibesession/config"
+	"github.com/jackwu/vibesession/launcher"
+	"github.com/jackwu/vibesession/model"
+	"github.com/jackwu/vibesession/scanner"
+	"github.com/jackwu/vibesession/source"
+)
+
+func init() {
+	source.Register(10, provider{})
+}
+
+type provider struct{}
+
+func (provider) Source() model.Source { return model.SourceClaude }
+func (provider) Label() string        { return "Claude Code" }
+func (provider) Color() string        { return "214" }
+
+func (p provider) Scan() ([]model.Session, []model.Diagnostic) {
+	return scanner.ScanClaude(p.Roots())
+}
+
+// Roots is $CLAUDE_CONFIG_DIR (or ~/.claude) and the claude_roots from the
+// config file.
+func (provider) Roots() []string {
+	cfg, _ := config.Load()
+	return config.Roots(config.ClaudeHome(), cfg.ClaudeRoots)
+}
+
+func (provider) ParseMessages(s model.Session) []model.Message {
+	return scanner.ParseClaudeMessages(s.FilePath, s.Children)
+}
+
+func (provider) ParseBranch(s model.Session, leaf string) []model.Message {
+	return scanner.ParseClaudeBranch(s.FilePath, s.Children, leaf)
+}
+
+func (provider) StreamMessages(s model.Session, leaf string, from model.Cursor, emit func([]model.Message, *model.Cursor) bool) {
+	scanner.StreamClaudeMessages(s.FilePath, s.Children, leaf, from, emit)
+}
+
+func (provider) ResumeCommand(s model.Session) string {
+	return launcher.Cd(s.CWD) + " && claude -r " + launcher.ShellQuote(s.ID)
+}
+
+func (provider) YoloCommand(s model.Session) string {
+	return launcher.Cd(s.CWD) + " && claude -r " + launcher.ShellQuote(s.ID) + " --dangerously-skip-permissions"
+}
+
+// ResumeNote warns that a session from an extra root (claude_roots) isn't
+// where claude looks for it. Pointing CLAUDE_CONFIG_DIR at that root would
+// find it, but run without the user's settings and credentials and write
+// the continued session into the copy.
+func (provider) ResumeNote(s model.Session) string {
+	if s.Root == "" || s.Root == filepath.Clean(config.ClaudeHome()) {
+		return ""
+	}
+	return "found in " + s.Root + "; claude only resumes sessions in " + config.ClaudeHome()
+}
+
+func (provider) NewCommand(dir string, yolo bool) string {
+	if yolo {
+		return launcher.Cd(dir) + " && claude --dangerously-skip-permissions"
+	}
+	return launcher.Cd(dir) + " && claude"
+}
@@ -0,0 +1,65 @@
+// Package codex registers the Codex CLI session provider.
+package codex
+
+import (
+	"path/filepath"
+
+	"github.com/jackwu/vibesession/config"
+	"github.com/jackwu/vibesession/launcher"
+	"github.com/jackwu/vibesession/model"
+	"github.com/jackwu/vibesession/scanner"
+	"github.com/jackwu/vibesession/source"
+)
+
+func init() {
+	source.Register(20, provider{})
+}
+
+type provider struct{}
+
+func (provider) Source() model.Source { return model.SourceCodex }
+func (provider) Label() string        { return "Codex" }
+func (provider) Color() string        { return "42" }
+
+func (p provider) Scan() ([]model.Session, []model.Diagnostic) {
+	return scanner.ScanCodex(p.Roots())
+}
+
+// Roots is $CODEX_HOME (or ~/.codex) and the codex_roots from the config
+// file.
+func (provider) Roots() []string {
+	cfg, _ := config.Load()
+	return config.Roots(config.CodexHome(), cfg.CodexRoots)
+}
+
+func (provider) ParseMessages(s model.Session) []model.Message {
+	return scanner.ParseCodexMessages(s.FilePath)
+}
+
+func (provider) StreamMessages(s model.Session, _ string, from model.Cursor, emit func([]model.Message, *model.Cursor) bool) {
+	scanner.StreamCodexMessages(s.FilePath, from, emit)
+}
+
+func (provider) ResumeCommand(s model.Session) string {
+	return launcher.Cd(s.CWD) + " && codex resume " + launcher.ShellQuote(s.ID)
+}
+
+func (provider) YoloCommand(s model.Session) string {
+	return launcher.Cd(s.CWD) + " && codex resume " + launcher.ShellQuote(s.ID) + " --full-auto"
+}
+
+// ResumeNote warns that a session from an extra root (codex_roots) isn't
+// where codex looks for it; see the Claude provider's.
+func (provider) ResumeNote(s model.Session) string {
+	if s.Root == "" || s.Root == filepath.Clean(config.CodexHome()) {
+		return ""
+	}
+	return "found in " + s.Root + "; codex only resumes sessions in " + config.CodexHome()
+}
+
+func (provider) NewCommand(dir string, yolo bool) string {
+	if yolo {
+		return launcher.Cd(dir) + " && codex --full-auto"
+	}
+	return launcher.Cd(dir) + " && codex"
+}
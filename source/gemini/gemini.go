@@ -0,0 +1,67 @@
+// Package gemini registers the Gemini CLI session provider.
+package gemini
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/jackwu/vibesession/launcher"
+	"github.com/jackwu/vibesession/model"
+	"github.com/jackwu/vibesession/scanner"
+	"github.com/jackwu/vibesession/source"
+)
+
+func init() {
+	source.Register(30, provider{})
+}
+
+type provider struct{}
+
+func (provider) Source() model.Source { return model.SourceGemini }
+func (provider) Label() string        { return "Gemini CLI" }
+func (provider) Color() string        { return "75" }
+
+func (provider) Scan() ([]model.Session, []model.Diagnostic) {
+	return scanner.ScanGemini()
+}
+
+func (provider) ParseMessages(s model.Session) []model.Message {
+	return scanner.ParseGeminiMessages(s.FilePath, s.ID)
+}
+
+func (provider) ResumeCommand(s model.Session) string {
+	args, ok := resumeArgs(s)
+	if !ok {
+		return ""
+	}
+	return launcher.Cd(s.CWD) + " && gemini" + args
+}
+
+func (provider) YoloCommand(s model.Session) string {
+	args, ok := resumeArgs(s)
+	if !ok {
+		return ""
+	}
+	return launcher.Cd(s.CWD) + " && gemini" + args + " --yolo"
+}
+
+func (provider) NewCommand(dir string, yolo bool) string {
+	if yolo {
+		return launcher.Cd(dir) + " && gemini --yolo"
+	}
+	return launcher.Cd(dir) + " && gemini"
+}
+
+// resumeArgs resumes a recorded chat by ID, or restores a /chat save
+// checkpoint through the interactive prompt. Sessions known only from
+// logs.json have nothing Gemini can resume, and sessions whose project root
+// is unknown have nowhere to resume in.
+func resumeArgs(s model.Session) (string, bool) {
+	if s.CWD == "" || filepath.Base(s.FilePath) == "logs.json" {
+		return "", false
+	}
+	if tag, ok := strings.CutPrefix(s.ID, scanner.GeminiCheckpointPrefix); ok {
+		return " --prompt-interactive " + launcher.ShellQuote("/chat resume "+tag), true
+	}
+	return " --resume " + launcher.ShellQuote(s.ID), true
+}
@@ -0,0 +1,200 @@
+// Package source defines the interface every agent CLI integration implements
+// and the registry the rest of vbs iterates over. A new CLI is supported by
+// adding a package that calls Register from init and blank-importing it in main.
+package source
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/jackwu/vibesession/model"
+)
+
+// Provider knows how to discover, read and launch sessions of one agent CLI.
+type Provider interface {
+	// Source is the value stored in model.Session.Source for this provider.
+	Source() model.Source
+	// Label is the human-readable tool name, e.g. "Claude Code".
+	Label() string
+	// Color is the lipgloss color (ANSI 256 code) used for the source tag.
+	Color() string
+
+	// Scan discovers all sessions on this machine, and reports the files
+	// that looked like transcripts but were skipped.
+	Scan() ([]model.Session, []model.Diagnostic)
+	// ParseMessages reads the full conversation of a session.
+	ParseMessages(s model.Session) []model.Message
+
+	// ResumeCommand returns the shell command to resume a session.
+	ResumeCommand(s model.Session) string
+	// YoloCommand returns the shell command to resume a session without approval prompts.
+	YoloCommand(s model.Session) string
+	// NewCommand returns the shell command to start a new session in dir.
+	NewCommand(dir string, yolo bool) string
+}
+
+// ProjectScanner is implemented by providers whose history lives inside
+// project directories instead of a central one. ScanAll runs them after the
+// other providers and passes in what those found, so the working
+// directories of known sessions are searched too.
+type ProjectScanner interface {
+	ScanProjects(known []model.Session) ([]model.Session, []model.Diagnostic)
+}
+
+// RootScanner is implemented by providers that read sessions from data
+// directories that can be moved (environment variables) or added to (the
+// config file). Roots lists them in the order they are scanned;
+// model.Session.Root records which one a session came from.
+type RootScanner interface {
+	Roots() []string
+}
+
+// ResumeNoter is implemented by providers whose resume command does more
+// or less than resume the selected session. ResumeNote explains what it
+// does; it is shown under the command before it runs.
+type ResumeNoter interface {
+	ResumeNote(s model.Session) string
+}
+
+// BranchParser is implemented by providers whose transcripts can branch,
+// for example when an earlier prompt is edited. ParseMessages reads the
+// active branch; ParseBranch reads the one ending at leaf, as listed in
+// the model.Fork of a message.
+type BranchParser interface {
+	ParseBranch(s model.Session, leaf string) []model.Message
+}
+
+// MessageStreamer is implemented by providers that can read a conversation
+// a batch of messages at a time, so long transcripts show up while they are
+// read and can be paged through without holding them in memory.
+// StreamMessages reads the branch ending at leaf ("" for the active one)
+// from the cursor from and passes each batch to emit, with the cursor the
+// next one starts at (nil after the last), until emit returns false.
+type MessageStreamer interface {
+	StreamMessages(s model.Session, leaf string, from model.Cursor, emit func(messages []model.Message, next *model.Cursor) bool)
+}
+
+type registered struct {
+	rank int
+	p    Provider
+}
+
+var (
+	registry  []registered
+	providers []Provider // sorted by rank
+)
+
+// Register adds a provider. Packages register from init, whose order follows
+// import paths, so rank decides the order providers appear in the TUI
+// (Tab filter, new-session form); lower ranks come first.
+func Register(rank int, p Provider) {
+	registry = append(registry, registered{rank, p})
+	sort.SliceStable(registry, func(i, j int) bool {
+		return registry[i].rank < registry[j].rank
+	})
+	providers = providers[:0]
+	for _, r := range registry {
+		providers = append(providers, r.p)
+	}
+}
+
+// All returns the registered providers in rank order.
+func All() []Provider {
+	return providers
+}
+
+// Lookup returns the provider for src, or nil if none is registered.
+func Lookup(src model.Source) Provider {
+	for _, p := range providers {
+		if p.Source() == src {
+			return p
+		}
+	}
+	return nil
+}
+
+// ScanAll runs every provider's scanner concurrently and merges the results.
+// ProjectScanner providers run afterwards, once the known sessions are in.
+func ScanAll() ([]model.Session, []model.Diagnostic) {
+	results := make([][]model.Session, len(providers))
+	diags := make([][]model.Diagnostic, len(providers))
+	var wg sync.WaitGroup
+	for i, p := range providers {
+		if _, ok := p.(ProjectScanner); ok {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, p Provider) {
+			defer wg.Done()
+			results[i], diags[i] = p.Scan()
+		}(i, p)
+	}
+	wg.Wait()
+
+	var known []model.Session
+	var skipped []model.Diagnostic
+	for i, r := range results {
+		known = append(known, r...)
+		skipped = append(skipped, diags[i]...)
+	}
+
+	all := known
+	for _, p := range providers {
+		if ps, ok := p.(ProjectScanner); ok {
+			found, d := ps.ScanProjects(known)
+			all = append(all, found...)
+			skipped = append(skipped, d...)
+		}
+	}
+	return all, skipped
+}
+
+// ParseMessages reads the conversation of s using its provider.
+func ParseMessages(s model.Session) []model.Message {
+	p := Lookup(s.Source)
+	if p == nil {
+		return nil
+	}
+	return p.ParseMessages(s)
+}
+
+// ParseBranch reads the branch of the conversation of s that ends at leaf.
+func ParseBranch(s model.Session, leaf string) []model.Message {
+	p := Lookup(s.Source)
+	if p == nil {
+		return nil
+	}
+	if bp, ok := p.(BranchParser); ok {
+		return bp.ParseBranch(s, leaf)
+	}
+	return p.ParseMessages(s)
+}
+
+// streamBatchSize is the batch size StreamMessages uses for providers that
+// read a whole conversation at once.
+const streamBatchSize = 50
+
+// StreamMessages reads the conversation of s incrementally, as described
+// for MessageStreamer. The conversation of a provider that can't stream is
+// read whole and handed on in batches.
+func StreamMessages(s model.Session, leaf string, from model.Cursor, emit func(messages []model.Message, next *model.Cursor) bool) {
+	p := Lookup(s.Source)
+	if p == nil {
+		emit(nil, nil)
+		return
+	}
+	if ms, ok := p.(MessageStreamer); ok {
+		ms.StreamMessages(s, leaf, from, emit)
+		return
+	}
+	msgs := ParseBranch(s, leaf)
+	for i := from.Index; ; i += streamBatchSize {
+		if i+streamBatchSize >= len(msgs) {
+			emit(msgs[min(i, len(msgs)):], nil)
+			return
+		}
+		if !emit(msgs[i:i+streamBatchSize], &model.Cursor{Index: i + streamBatchSize}) {
+			return
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package tts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Check is the outcome of one health check run by "vbs doctor".
+type Check struct {
+	OK    bool
+	Label string // what was checked and found
+	Fix   string // what to do about a failed check
+}
+
+// Installed reports whether "vbs tts setup" has been run, judging by the
+// config and the hook script it writes.
+func Installed() bool {
+	for _, path := range []string{configPath(), hookPath()} {
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Doctor checks everything the Stop hook needs to speak a reply: the
+// config, both scripts, the hook entry in settings.json, the programs the
+// scripts run, the queue directory and the worker's PID file.
+func Doctor() []Check {
+	var checks []Check
+	add := func(ok bool, label, fix string) {
+		checks = append(checks, Check{OK: ok, Label: label, Fix: fix})
+	}
+
+	if cfg, err := readConfig(); err != nil {
+		add(false, fmt.Sprintf("config %s: %v", configPath(), err), "Run: vbs tts setup")
+	} else {
+		state := "on"
+		if !cfg.Enabled {
+			state = "off (vbs tts on)"
+		}
+		add(true, fmt.Sprintf("config %s, TTS is %s", configPath(), state), "")
+	}
+
+	for _, script := range []struct{ name, path string }{
+		{"hook script", hookPath()},
+		{"worker script", workerPath()},
+	} {
+		info, err := os.Stat(script.path)
+		switch {
+		case err != nil:
+			add(false, fmt.Sprintf("%s %s missing", script.name, script.path), "Run: vbs tts setup")
+		case info.Mode()&0111 == 0:
+			add(false, fmt.Sprintf("%s %s is not executable", script.name, script.path), "Run: chmod +x "+script.path)
+		default:
+			add(true, fmt.Sprintf("%s %s", script.name, script.path), "")
+		}
+	}
+
+	add(checkStopHook())
+
+	for _, dep := range []struct{ name, fix string }{
+		{"jq", "Install: brew install jq"},
+		{"python3", "Install: brew install python3"},
+		{"edge-tts", "Install: pipx install edge-tts (or: pip3 install edge-tts)"},
+		{"afplay", "afplay ships with macOS; the worker uses it to play replies"},
+	} {
+		if path, err := exec.LookPath(dep.name); err != nil {
+			add(false, dep.name+" not found", dep.fix)
+		} else {
+			add(true, dep.name+" found at "+path, "")
+		}
+	}
+
+	add(checkQueueDir())
+	add(checkWorkerPID())
+	return checks
+}
+
+// checkStopHook looks for hookPath among the Stop hooks in settings.json.
+func checkStopHook() (bool, string, string) {
+	data, err := os.ReadFile(settingsPath())
+	if err != nil {
+		return false, fmt.Sprintf("settings %s: %v", settingsPath(), err), "Run: vbs tts setup"
+	}
+	var settings map[string]interface{}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return false, fmt.Sprintf("settings %s is not valid JSON: %v", settingsPath(), err),
+			"Fix the JSON by hand, then run: vbs tts setup"
+	}
+	if !hasStopHook(settings) {
+		return false, fmt.Sprintf("no Stop hook running %s in %s", hookPath(), settingsPath()), "Run: vbs tts setup"
+	}
+	return true, "Stop hook registered in " + settingsPath(), ""
+}
+
+// checkQueueDir makes sure the hook can write tasks to the queue directory,
+// by writing one that isn't a .json task the worker would pick up.
+func checkQueueDir() (bool, string, string) {
+	info, err := os.Stat(queueDir)
+	if os.IsNotExist(err) {
+		return true, "queue directory " + queueDir + " (created on the next reply)", ""
+	}
+	if err != nil || !info.IsDir() {
+		return false, "queue directory " + queueDir + " is not a directory", "Run: rm -f " + queueDir
+	}
+	f, err := os.CreateTemp(queueDir, "doctor-*.tmp")
+	if err != nil {
+		return false, fmt.Sprintf("queue directory %s is not writable: %v", queueDir, err),
+			"Run: sudo chown -R $USER " + queueDir
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return true, "queue directory " + queueDir + " is writable", ""
+}
+
+// checkWorkerPID reports a PID file left by a worker that is no longer
+// running, e.g. after it was killed.
+func checkWorkerPID() (bool, string, string) {
+	data, err := os.ReadFile(workerPidFile)
+	if os.IsNotExist(err) {
+		return true, "no worker running", ""
+	}
+	if err != nil {
+		return false, fmt.Sprintf("worker PID file %s: %v", workerPidFile, err), "Run: rm -f " + workerPidFile
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return false, "worker PID file " + workerPidFile + " is corrupt", "Run: rm -f " + workerPidFile
+	}
+	if alive(pid) {
+		return true, fmt.Sprintf("worker running (pid %d)", pid), ""
+	}
+	return false, fmt.Sprintf("stale worker PID file %s (pid %d is not running)", workerPidFile, pid),
+		"Run: rm -f " + workerPidFile
+}
+
+// alive reports whether a process with the given pid exists.
+func alive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM) // EPERM: another user's process
+}
@@ -6,6 +6,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+
+	"github.com/jackwu/vibesession/config"
 )
 
 // Config holds TTS configuration
@@ -33,19 +35,19 @@ func configPath() string {
 	return filepath.Join(home, ".config", "vbs", "tts.json")
 }
 
+// The scripts and settings live in Claude Code's own data directory, which
+// $CLAUDE_CONFIG_DIR moves.
+
 func hookPath() string {
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, ".claude", "hooks", "tts-speak.sh")
+	return filepath.Join(config.ClaudeHome(), "hooks", "tts-speak.sh")
 }
 
 func workerPath() string {
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, ".claude", "hooks", "tts-worker.sh")
+	return filepath.Join(config.ClaudeHome(), "hooks", "tts-worker.sh")
 }
 
 func settingsPath() string {
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, ".claude", "settings.json")
+	return filepath.Join(config.ClaudeHome(), "settings.json")
 }
 
 func readConfig() (Config, error) {
@@ -293,25 +295,11 @@ func updateSettings() error {
 	}
 
 	// check if Stop hook already exists with our command
-	if existing, ok := hooks["Stop"]; ok {
-		if arr, ok := existing.([]interface{}); ok {
-			for _, item := range arr {
-				if m, ok := item.(map[string]interface{}); ok {
-					if innerHooks, ok := m["hooks"].([]interface{}); ok {
-						for _, h := range innerHooks {
-							if hm, ok := h.(map[string]interface{}); ok {
-								if hm["command"] == hookPath() {
-									return nil
-								}
-							}
-						}
-					}
-				}
-			}
-			hooks["Stop"] = append(arr, stopHookEntry)
-		} else {
-			hooks["Stop"] = []interface{}{stopHookEntry}
-		}
+	if hasStopHook(settings) {
+		return nil
+	}
+	if arr, ok := hooks["Stop"].([]interface{}); ok {
+		hooks["Stop"] = append(arr, stopHookEntry)
 	} else {
 		hooks["Stop"] = []interface{}{stopHookEntry}
 	}
@@ -323,6 +311,26 @@ func updateSettings() error {
 	return os.WriteFile(settingsPath(), append(out, '\n'), 0644)
 }
 
+// hasStopHook reports whether settings run hookPath as a Stop hook.
+func hasStopHook(settings map[string]interface{}) bool {
+	hooks, _ := settings["hooks"].(map[string]interface{})
+	arr, _ := hooks["Stop"].([]interface{})
+	for _, item := range arr {
+		if m, ok := item.(map[string]interface{}); ok {
+			if innerHooks, ok := m["hooks"].([]interface{}); ok {
+				for _, h := range innerHooks {
+					if hm, ok := h.(map[string]interface{}); ok {
+						if hm["command"] == hookPath() {
+							return true
+						}
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
 // ---------------------------------------------------------------------------
 // Hook script: extracts text, enqueues task, ensures worker is running.
 // No set -e. All failures are silent. Always exits 0.
@@ -417,7 +425,7 @@ var hookScript = "#!/bin/bash\n" +
 	"QUEUEDIR=\"/tmp/vbs-tts-queue\"\n" +
 	"PIDFILE=\"/tmp/vbs-tts-worker.pid\"\n" +
 	"WORKERLOCK=\"/tmp/vbs-tts-worker-start.lock\"\n" +
-	"WORKER=\"$HOME/.claude/hooks/tts-worker.sh\"\n" +
+	"WORKER=\"$(dirname \"$0\")/tts-worker.sh\"\n" +
 	"\n" +
 	"if [ \"$OVERLAP\" = \"interrupt\" ]; then\n" +
 	"  # Interrupt mode: clear queue, kill worker & playback, enqueue, start fresh worker\n" +
@@ -3,17 +3,27 @@ package tui
 import (
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/jackwu/vibesession/launcher"
 	"github.com/jackwu/vibesession/model"
+	"github.com/jackwu/vibesession/pricing"
+	"github.com/jackwu/vibesession/query"
+	"github.com/jackwu/vibesession/search"
+	"github.com/jackwu/vibesession/source"
 )
 
 type mode int
 
+// recencyBonus is the ranking bonus of a session active just now; it halves
+// after a week. A well-placed matched character is worth about 16-40.
+const recencyBonus = 30.0
+
 const (
 	modeList mode = iota
 	modeSearch
@@ -21,6 +31,7 @@ const (
 	modeDetail
 	modeDetailSearch
 	modeNew
+	modeFullText
 )
 
 type Model struct {
@@ -33,8 +44,15 @@ type Model struct {
 	mode        mode
 	searchInput textinput.Model
 	cmdInput    textinput.Model
-	filter      string // "all", "claude", "codex"
-	launchCmd   string // final command to execute
+	filter      string      // "all" or a lowercased model.Source, e.g. "claude"
+	filterQuery query.Query // parsed from searchInput
+	queryErr    error       // why searchInput doesn't parse, if it doesn't
+	showCost    bool        // show the optional cost column
+	byStart     bool        // sort and show by start time instead of last activity
+	fuzzyMatch  bool        // match search words fuzzily instead of as substrings
+	showPrompt  bool        // show every session's first prompt, not the title where there is one
+	launchCmd   string      // final command to execute
+	cmdNote     string      // caveat about the resume command, if any
 	quitting    bool
 
 	// tracks mode before entering command mode, so Esc returns correctly
@@ -43,6 +61,9 @@ type Model struct {
 	// current working directory (for new session)
 	cwd string
 
+	// transcript files the scan skipped, counted in the title bar
+	skipped int
+
 	// new session form
 	newForm *newForm
 
@@ -56,60 +77,107 @@ type Model struct {
 	detailSearchQuery string
 	detailMatches     []int // line indices of search matches
 	detailMatchIdx    int   // current match index
+
+	// expandable items (Task calls with a subagent transcript)
+	detailItems     []detailItem               // in render order
+	detailItemIdx   int                        // selected item, -1 when none
+	detailExpanded  map[string]bool            // expanded items by key
+	detailSubagents map[string][]model.Message // loaded subagent conversations by file path
+	detailMsgLines  []int                      // first line of each message in detailLines
+	detailJump      *search.Result             // full-text hit to scroll to once loaded
+	detailReturn    mode                       // mode to return to on Esc
+	detailThinking  bool                       // show the model's reasoning
+	detailForkAt    int                        // Index of the message whose branch is being switched, -1 if none
+	detailForkRow   int                        // screen row to keep that fork at
+
+	// long conversations are read and shown a page at a time
+	detailStream *messageStream // reading the page in the background, nil once done
+	detailLeaf   string         // the branch shown, "" for the active one
+	detailBase   int            // Index of the first loaded message
+	detailFrom   model.Cursor   // where the loaded page starts
+	detailNext   *model.Cursor  // where the next page starts, nil on the last page
+	detailPrev   []model.Cursor // where the earlier pages start
+
+	// full-text search
+	ftInput   textinput.Model
+	ftIndex   *fullTextIndex // built by the first search
+	ftIndexed bool           // whether a search has returned, so the index is built
+	ftQuery   string         // query of ftResults
+	ftPending string         // query being searched
+	ftLoading bool
+	ftResults []search.Result
+	ftCursor  int
+	ftOffset  int
 }
 
 func NewModel(sessions []model.Session) Model {
-	// sort by time descending
-	sort.Slice(sessions, func(i, j int) bool {
-		return sessions[i].Time.After(sessions[j].Time)
-	})
-
 	si := textinput.New()
-	si.Placeholder = "search..."
+	si.Placeholder = "words, \"phrase\", -exclude, source: project: after: before: tool: file:"
 	si.CharLimit = 100
 
 	ci := textinput.New()
 	ci.CharLimit = 500
 
+	fi := textinput.New()
+	fi.Placeholder = "words in any message..."
+	fi.CharLimit = 100
+
 	m := Model{
 		sessions:    sessions,
 		filter:      "all",
 		searchInput: si,
 		cmdInput:    ci,
+		ftInput:     fi,
+		ftIndex:     &fullTextIndex{},
 		width:       120,
 		height:      30,
 	}
+	m.sortSessions()
 	m.applyFilter()
 	return m
 }
 
+// sessionTime is the time shown and sorted by in the list.
+func (m Model) sessionTime(s model.Session) time.Time {
+	if m.byStart {
+		return s.Started()
+	}
+	return s.Updated()
+}
+
+// sortSessions orders sessions newest first by the current time column.
+func (m *Model) sortSessions() {
+	sort.SliceStable(m.sessions, func(i, j int) bool {
+		return m.sessionTime(m.sessions[i]).After(m.sessionTime(m.sessions[j]))
+	})
+}
+
 func (m *Model) applyFilter() {
 	m.filtered = nil
-	search := strings.ToLower(m.searchInput.Value())
+	// a malformed query keeps the last valid one while the error is shown
+	if q, err := query.Parse(m.searchInput.Value()); err != nil {
+		m.queryErr = err
+	} else {
+		m.filterQuery = q
+		m.queryErr = nil
+	}
+	m.filterQuery = m.filterQuery.WithFuzzy(m.fuzzyMatch).WithPrompt(m.showPrompt)
 
 	for _, s := range m.sessions {
 		// source filter
-		switch m.filter {
-		case "claude":
-			if s.Source != model.SourceClaude {
-				continue
-			}
-		case "codex":
-			if s.Source != model.SourceCodex {
-				continue
-			}
+		if m.filter != "all" && strings.ToLower(string(s.Source)) != m.filter {
+			continue
 		}
 
-		// text search
-		if search != "" {
-			haystack := strings.ToLower(s.Summary + " " + s.Project + " " + s.ID + " " + s.TeamName)
-			if !strings.Contains(haystack, search) {
-				continue
-			}
+		if !m.filterQuery.Match(s) {
+			continue
 		}
 
 		m.filtered = append(m.filtered, s)
 	}
+	if m.filterQuery.Ranked() {
+		m.rankFiltered()
+	}
 
 	// reset cursor
 	if m.cursor >= len(m.filtered) {
@@ -118,6 +186,24 @@ func (m *Model) applyFilter() {
 	m.clampOffset()
 }
 
+// rankFiltered orders fuzzy matches best first, favoring recent sessions
+// among similar matches.
+func (m *Model) rankFiltered() {
+	now := time.Now()
+	scores := make(map[string]int, len(m.filtered))
+	for _, s := range m.filtered {
+		days := now.Sub(m.sessionTime(s)).Hours() / 24
+		if days < 0 {
+			days = 0
+		}
+		scores[s.FilePath+"\x00"+s.ID] = m.filterQuery.Score(s) + int(recencyBonus/(1+days/7))
+	}
+	sort.SliceStable(m.filtered, func(i, j int) bool {
+		a, b := m.filtered[i], m.filtered[j]
+		return scores[a.FilePath+"\x00"+a.ID] > scores[b.FilePath+"\x00"+b.ID]
+	})
+}
+
 func (m Model) Init() tea.Cmd {
 	return nil
 }
@@ -130,7 +216,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.clampOffset()
 		// re-render detail lines if in detail mode (width may have changed)
 		if (m.mode == modeDetail || m.mode == modeDetailSearch) && !m.detailLoading && m.detailMessages != nil {
-			m.detailLines = m.renderDetailContent()
+			m.renderDetail()
 			m.detailScrollDown(0) // clamp offset to new bounds
 			if m.detailSearchQuery != "" {
 				m.computeSearchMatches()
@@ -138,8 +224,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case messagesLoadedMsg:
-		m = m.updateDetailLoaded(msg.filePath, msg.messages)
+	case messageBatchMsg:
+		return m.updateDetailBatch(msg)
+
+	case subagentLoadedMsg:
+		m = m.updateSubagentLoaded(msg)
+		return m, nil
+
+	case fullTextResultsMsg:
+		m = m.updateFullTextResults(msg)
 		return m, nil
 
 	case tea.KeyMsg:
@@ -156,6 +249,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateDetailSearch(msg)
 		case modeNew:
 			return m.updateNewForm(msg)
+		case modeFullText:
+			return m.updateFullText(msg)
 		}
 	}
 	return m, nil
@@ -207,9 +302,13 @@ func (m Model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if len(m.filtered) > 0 {
 			s := m.filtered[m.cursor]
 			cmd := launcher.BuildCommand(s)
+			if cmd == "" {
+				break // the session can't be resumed
+			}
 			m.cmdInput.SetValue(cmd)
 			m.cmdInput.Focus()
 			m.cmdInput.CursorEnd()
+			m.cmdNote = launcher.ResumeNote(s)
 			m.prevMode = modeList
 			m.mode = modeCommand
 		}
@@ -235,27 +334,57 @@ func (m Model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.searchInput.Focus()
 		m.mode = modeSearch
 
+	case "ctrl+f":
+		m.fuzzyMatch = !m.fuzzyMatch
+		m.applyFilter()
+
 	case "tab":
-		switch m.filter {
-		case "all":
-			m.filter = "claude"
-		case "claude":
-			m.filter = "codex"
-		case "codex":
-			m.filter = "all"
-		}
+		m.filter = nextFilter(m.filter)
+		m.applyFilter()
+
+	case "c":
+		m.showCost = !m.showCost
+
+	case "f":
+		return m.enterFullText()
+
+	case "t":
+		m.byStart = !m.byStart
+		m.sortSessions()
+		m.applyFilter()
+
+	case "s":
+		m.showPrompt = !m.showPrompt
 		m.applyFilter()
 	}
 
 	return m, nil
 }
 
+// nextFilter cycles "all" -> each registered source -> "all".
+func nextFilter(current string) string {
+	filters := []string{"all"}
+	for _, p := range source.All() {
+		filters = append(filters, strings.ToLower(string(p.Source())))
+	}
+	for i, f := range filters {
+		if f == current {
+			return filters[(i+1)%len(filters)]
+		}
+	}
+	return "all"
+}
+
 func (m Model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "enter", "esc":
 		m.searchInput.Blur()
 		m.mode = modeList
 		return m, nil
+	case "ctrl+f":
+		m.fuzzyMatch = !m.fuzzyMatch
+		m.applyFilter()
+		return m, nil
 	}
 
 	var cmd tea.Cmd
@@ -295,11 +424,22 @@ func (m Model) View() string {
 		return m.viewNewForm()
 	}
 
+	if m.mode == modeFullText {
+		return m.viewFullText()
+	}
+
 	var b strings.Builder
 
 	// title bar
 	title := titleStyle.Render("VibeSession")
-	filterInfo := dimStyle.Render(fmt.Sprintf("  [%s]  %d sessions", m.filter, len(m.filtered)))
+	matching := "exact"
+	if m.fuzzyMatch {
+		matching = "fuzzy"
+	}
+	filterInfo := dimStyle.Render(fmt.Sprintf("  [%s]  %d sessions  %s", m.filter, len(m.filtered), matching))
+	if m.skipped > 0 {
+		filterInfo += warningStyle.Render(fmt.Sprintf("  ⚠ %d skipped (vbs doctor)", m.skipped))
+	}
 	b.WriteString(title + filterInfo + "\n")
 
 	// header row
@@ -329,11 +469,23 @@ func (m Model) View() string {
 	switch m.mode {
 	case modeSearch:
 		b.WriteString(statusBarStyle.Render("Search: ") + m.searchInput.View())
+		if m.queryErr != nil {
+			b.WriteString("  " + errorStyle.Render(m.queryErr.Error()))
+		} else {
+			b.WriteString(helpStyle.Render("  ctrl+f: fuzzy/exact"))
+		}
 	case modeCommand:
 		b.WriteString(statusBarStyle.Render("Command: ") + m.cmdInput.View())
 		b.WriteString("\n")
 		b.WriteString(helpStyle.Render("  Enter: execute  Esc: cancel"))
+		if m.cmdNote != "" {
+			b.WriteString("  " + warningStyle.Render(m.cmdNote))
+		}
 	default:
+		if m.queryErr != nil {
+			b.WriteString(errorStyle.Render("  Query: "+m.queryErr.Error()) + helpStyle.Render("  (/ to edit)"))
+			break
+		}
 		b.WriteString(m.renderHelp())
 	}
 
@@ -345,64 +497,192 @@ func (m Model) renderHeader() string {
 	cols := []string{
 		pad("Source", w.source),
 		pad("Session ID", w.id),
-		pad("Time", w.time),
+		pad(m.timeLabel(), w.time),
 		pad("Project", w.project),
-		pad("Summary", w.summary),
 	}
+	if w.branch > 0 {
+		cols = append(cols, pad("Branch", w.branch))
+	}
+	if w.model > 0 {
+		cols = append(cols, pad("Model", w.model))
+	}
+	if w.cost > 0 {
+		cols = append(cols, padLeft("Cost", w.cost))
+	}
+	summaryLabel := "Summary"
+	if m.showPrompt {
+		summaryLabel = "First prompt"
+	}
+	cols = append(cols, pad(summaryLabel, w.summary))
 	return headerStyle.Render(strings.Join(cols, " "))
 }
 
 func (m Model) renderRow(s model.Session, selected bool) string {
 	w := m.colWidths()
 
-	var sourceStr string
-	switch s.Source {
-	case model.SourceClaude:
-		sourceStr = claudeTag.Render(pad("Claude", w.source))
-	case model.SourceCodex:
-		sourceStr = codexTag.Render(pad("Codex", w.source))
+	timeStr := m.sessionTime(s).Format("01-02 15:04")
+	headline := s.Headline()
+	if m.showPrompt {
+		headline = s.Summary
 	}
 
-	timeStr := s.Time.Format("01-02 15:04")
-	summaryStr := s.Summary
+	// markers go before the headline; matches are highlighted in the team
+	// name and the headline, offset by what precedes them
+	var prefix string
+	if s.Archived {
+		prefix += "[archived] "
+	}
+	if s.Compactions > 0 {
+		prefix += fmt.Sprintf("[compacted:%d] ", s.Compactions)
+	}
+	if len(s.Children) > 0 {
+		prefix += fmt.Sprintf("[agents:%d] ", len(s.Children))
+	}
+	summaryMatches := make(map[int]bool)
+	addMatches := func(field string) {
+		at := len([]rune(prefix))
+		for i := range m.filterQuery.Positions(s, field) {
+			summaryMatches[at+i] = true
+		}
+	}
 	if s.TeamName != "" {
-		summaryStr = "[team:" + s.TeamName + "] " + summaryStr
+		prefix += "[team:"
+		addMatches(s.TeamName)
+		prefix += s.TeamName + "] "
 	}
+	addMatches(headline)
+	summaryStr := prefix + headline
 	summaryRunes := []rune(summaryStr)
 	if len(summaryRunes) > w.summary {
 		summaryStr = string(summaryRunes[:w.summary-2]) + ".."
+		for i := range summaryMatches {
+			if i >= w.summary-2 {
+				delete(summaryMatches, i)
+			}
+		}
 	}
 
-	cols := []string{
-		sourceStr,
-		pad(s.ShortID, w.id),
-		pad(timeStr, w.time),
-		pad(s.Project, w.project),
-		summaryStr,
+	// columns after the source, shared by the plain and selected rows;
+	// characters matched by the search are highlighted in project and summary
+	var segs []segment
+	addCol := func(text string, matches map[int]bool) {
+		if len(segs) > 0 {
+			segs = append(segs, segment{text: " "})
+		}
+		segs = append(segs, splitMatches(text, matches)...)
 	}
-
-	row := strings.Join(cols, " ")
+	addCol(pad(s.ShortID, w.id), nil)
+	addCol(pad(timeStr, w.time), nil)
+	addCol(pad(s.Project, w.project), m.filterQuery.Positions(s, s.Project))
+	if w.branch > 0 {
+		addCol(pad(s.GitBranch, w.branch), nil)
+	}
+	if w.model > 0 {
+		addCol(pad(shortModel(s.Model), w.model), nil)
+	}
+	if w.cost > 0 {
+		addCol(padLeft(sessionCost(s), w.cost), nil)
+	}
+	addCol(summaryStr, summaryMatches)
 
 	if selected {
-		// re-render with selected style, stripping existing styles on source
-		plainSource := pad(string(s.Source), w.source)
-		plainCols := []string{
-			plainSource,
-			pad(s.ShortID, w.id),
-			pad(timeStr, w.time),
-			pad(s.Project, w.project),
-			summaryStr,
-		}
-		row = selectedStyle.Render(strings.Join(plainCols, " "))
+		// render with selected style, without the colored source tag
+		segs = append([]segment{{text: " " + pad(string(s.Source), w.source) + " "}}, segs...)
+		segs = append(segs, segment{text: " "})
+		row := renderSegments(segs, selectedTextStyle, selectedMatchStyle)
 		// pad to full width
-		row = lipgloss.PlaceHorizontal(m.width, lipgloss.Left, row)
+		return lipgloss.PlaceHorizontal(m.width, lipgloss.Left, row)
+	}
+
+	return sourceTag(s.Source).Render(pad(string(s.Source), w.source)) + " " +
+		renderSegments(segs, lipgloss.NewStyle(), matchStyle)
+}
+
+// segment is a run of row text that is or isn't part of a search match.
+type segment struct {
+	text  string
+	match bool
+}
+
+// splitMatches splits text into runs of matched and unmatched runes.
+func splitMatches(text string, matches map[int]bool) []segment {
+	if len(matches) == 0 {
+		return []segment{{text: text}}
+	}
+	var segs []segment
+	var cur []rune
+	curMatch := false
+	for i, r := range []rune(text) {
+		if matches[i] != curMatch && len(cur) > 0 {
+			segs = append(segs, segment{text: string(cur), match: curMatch})
+			cur = cur[:0]
+		}
+		curMatch = matches[i]
+		cur = append(cur, r)
+	}
+	return append(segs, segment{text: string(cur), match: curMatch})
+}
+
+func renderSegments(segs []segment, base, match lipgloss.Style) string {
+	var b strings.Builder
+	for _, sg := range segs {
+		if sg.match {
+			b.WriteString(match.Render(sg.text))
+		} else {
+			b.WriteString(base.Render(sg.text))
+		}
+	}
+	return b.String()
+}
+
+func (m Model) timeLabel() string {
+	if m.byStart {
+		return "Started"
+	}
+	return "Updated"
+}
+
+// formatDuration renders d compactly: "45s", "12m", "1h05m", "3d4h".
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	default:
+		return fmt.Sprintf("%dd%dh", int(d.Hours())/24, int(d.Hours())%24)
 	}
+}
 
-	return row
+// sessionCost shows the estimated cost of a session, or its token count
+// when none of its models has a known price.
+func sessionCost(s model.Session) string {
+	cost := pricing.FormatCost(s.Usage)
+	if cost == "?" {
+		return pricing.FormatTokens(s.TotalUsage().Total()) + " tok"
+	}
+	return cost
+}
+
+// shortModel drops the date suffix of a model name
+// ("claude-sonnet-4-5-20250929" -> "claude-sonnet-4-5").
+func shortModel(name string) string {
+	if i := strings.LastIndex(name, "-"); i >= 0 && len(name)-i-1 == 8 {
+		if _, err := strconv.Atoi(name[i+1:]); err == nil {
+			return name[:i]
+		}
+	}
+	return name
 }
 
 func (m Model) renderHelp() string {
-	return helpStyle.Render("  Enter: open  y: yolo  n: new  v: view  /: search  Tab: filter  q: quit")
+	toggle := "s: show first prompts"
+	if m.showPrompt {
+		toggle = "s: show titles"
+	}
+	return helpStyle.Render("  Enter: open  y: yolo  n: new  v: view  /: search  f: find in messages  Tab: filter  t: time  " + toggle + "  c: cost  q: quit")
 }
 
 type colWidths struct {
@@ -410,6 +690,9 @@ type colWidths struct {
 	id      int
 	time    int
 	project int
+	branch  int // 0 when the column is hidden
+	model   int // 0 when the column is hidden
+	cost    int // 0 when the column is hidden
 	summary int
 }
 
@@ -422,6 +705,19 @@ func (m Model) colWidths() colWidths {
 	}
 	// summary gets remaining width
 	used := w.source + w.id + w.time + w.project + 6 // 6 for separators and padding
+	if m.showCost {
+		w.cost = 10
+		used += w.cost + 1
+	}
+	// optional columns only when the summary keeps a useful width
+	if m.width >= 120 {
+		w.branch = 14
+		used += w.branch + 1
+	}
+	if m.width >= 150 {
+		w.model = 18
+		used += w.model + 1
+	}
 	w.summary = m.width - used
 	if w.summary < 20 {
 		w.summary = 20
@@ -459,6 +755,18 @@ func (m *Model) SetCWD(cwd string) {
 	m.cwd = cwd
 }
 
+// SetDiagnostics records the transcript files the scan skipped, so the
+// list can point at "vbs doctor" for the details. Empty files are left out,
+// as they are in the doctor's problem count.
+func (m *Model) SetDiagnostics(diags []model.Diagnostic) {
+	m.skipped = 0
+	for _, d := range diags {
+		if d.Reason != model.ReasonEmpty {
+			m.skipped++
+		}
+	}
+}
+
 // LaunchCmd returns the command to execute after TUI exits.
 func (m Model) LaunchCmd() string {
 	return m.launchCmd
@@ -472,6 +780,14 @@ func pad(s string, width int) string {
 	return s + strings.Repeat(" ", width-len(runes))
 }
 
+func padLeft(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) >= width {
+		return string(runes[:width])
+	}
+	return strings.Repeat(" ", width-len(runes)) + s
+}
+
 func max(a, b int) int {
 	if a > b {
 		return a
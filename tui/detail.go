@@ -6,55 +6,74 @@ import (
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/jackwu/vibesession/launcher"
 	"github.com/jackwu/vibesession/model"
-	"github.com/jackwu/vibesession/scanner"
+	"github.com/jackwu/vibesession/pricing"
+	"github.com/jackwu/vibesession/search"
+	"github.com/jackwu/vibesession/source"
 )
 
-// messagesLoadedMsg is sent when async message parsing completes.
-type messagesLoadedMsg struct {
-	filePath string // identifies which session this result belongs to
+// subagentLoadedMsg is sent when a subagent transcript has been parsed.
+type subagentLoadedMsg struct {
+	parent   string // FilePath of the session the subagent belongs to
+	filePath string
 	messages []model.Message
 }
 
-func loadMessages(s model.Session) tea.Cmd {
-	filePath := s.FilePath
-	source := s.Source
-	return func() tea.Msg {
-		msgs := scanner.ParseMessages(filePath, source)
-		return messagesLoadedMsg{filePath: filePath, messages: msgs}
-	}
-}
-
 func (m Model) enterDetail() (Model, tea.Cmd) {
 	if len(m.filtered) == 0 {
 		return m, nil
 	}
-	m.detailSession = m.filtered[m.cursor]
-	m.detailMessages = nil
-	m.detailLines = nil
-	m.detailOffset = 0
-	m.detailLoading = true
+	m.detailJump = nil
+	m.detailReturn = modeList
+	return m.openDetail(m.filtered[m.cursor])
+}
+
+// openDetail shows the conversation of s, loading it in the background.
+func (m Model) openDetail(s model.Session) (Model, tea.Cmd) {
+	m.detailSession = s
 	m.detailSearchInput = textinput.New()
 	m.detailSearchInput.Placeholder = "search..."
 	m.detailSearchInput.CharLimit = 100
 	m.detailSearchQuery = ""
-	m.detailMatches = nil
-	m.detailMatchIdx = 0
+	m.detailSubagents = make(map[string][]model.Message)
+	m.detailForkAt = -1
+	m.detailLeaf = ""
+	m.detailPrev = nil
 	m.mode = modeDetail
-	return m, loadMessages(m.detailSession)
+	return m.loadPage(model.Cursor{})
 }
 
-func (m Model) updateDetailLoaded(filePath string, msgs []model.Message) Model {
-	// discard stale result if user already switched to a different session
-	if filePath != m.detailSession.FilePath {
+// jumpToHit highlights the matched term and scrolls to its first
+// occurrence in the matched message.
+func (m *Model) jumpToHit(r search.Result) {
+	mi := r.Message - m.detailBase
+	if mi < 0 || mi >= len(m.detailMsgLines) {
+		return
+	}
+	start := m.detailMsgLines[mi]
+	if r.Term == "" {
+		m.detailScrollToLine(start)
+		return
+	}
+	m.detailSearchQuery = r.Term
+	m.computeSearchMatches()
+	for i, line := range m.detailMatches {
+		if line >= start {
+			m.detailMatchIdx = i
+			m.detailScrollToMatch(i)
+			return
+		}
+	}
+}
+
+func (m Model) updateSubagentLoaded(msg subagentLoadedMsg) Model {
+	if msg.parent != m.detailSession.FilePath {
 		return m
 	}
-	m.detailMessages = msgs
-	m.detailLoading = false
-	m.detailLines = m.renderDetailContent()
-	m.detailOffset = 0
+	m.detailSubagents[msg.filePath] = msg.messages
+	m.renderDetail()
 	return m
 }
 
@@ -63,14 +82,19 @@ func (m Model) updateDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	switch key {
 	case "esc", "q":
-		m.mode = modeList
+		m.stopDetailStream()
+		m.mode = m.detailReturn
 		return m, nil
 
 	case "enter":
 		cmd := launcher.BuildCommand(m.detailSession)
+		if cmd == "" {
+			return m, nil // the session can't be resumed
+		}
 		m.cmdInput.SetValue(cmd)
 		m.cmdInput.Focus()
 		m.cmdInput.CursorEnd()
+		m.cmdNote = launcher.ResumeNote(m.detailSession)
 		m.prevMode = modeDetail
 		m.mode = modeCommand
 		return m, nil
@@ -106,6 +130,27 @@ func (m Model) updateDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.detailNextMatch()
 	case "N":
 		m.detailPrevMatch()
+
+	case "tab":
+		m.detailSelectItem(1)
+	case "shift+tab":
+		m.detailSelectItem(-1)
+	case " ":
+		return m.detailToggleItem()
+
+	case "t":
+		m.detailThinking = !m.detailThinking
+		m.rerenderDetailItems()
+
+	case "[":
+		return m.detailSwitchBranch(-1)
+	case "]":
+		return m.detailSwitchBranch(1)
+
+	case ">":
+		return m.detailNextPage()
+	case "<":
+		return m.detailPrevPage()
 	}
 
 	return m, nil
@@ -134,11 +179,38 @@ func (m Model) viewDetail() string {
 	var b strings.Builder
 
 	// title bar
-	title := detailTitleStyle.Render(fmt.Sprintf(" %s — %s — %s",
+	titleText := fmt.Sprintf(" %s — %s — %s",
 		m.detailSession.Source,
 		m.detailSession.ShortID,
 		m.detailSession.Project,
-	))
+	)
+	if branch := m.detailSession.GitBranch; branch != "" {
+		titleText += " (" + branch + ")"
+	}
+	if name := m.detailSession.Model; name != "" {
+		titleText += " — " + shortModel(name)
+	}
+	if v := m.detailSession.CLIVersion; v != "" {
+		titleText += " — v" + strings.TrimPrefix(v, "v")
+	}
+	if m.detailPaged() {
+		titleText += " — " + m.detailPageRange()
+	} else if n := m.detailSession.MessageCount; n > 0 {
+		titleText += fmt.Sprintf(" — %d msgs", n)
+		if d := m.detailSession.Duration(); d > 0 {
+			titleText += " in " + formatDuration(d)
+		}
+	}
+	if n := m.detailSession.Compactions; n > 0 {
+		titleText += fmt.Sprintf(" — compacted %d×", n)
+	}
+	if total := m.detailSession.TotalUsage().Total(); total > 0 {
+		titleText += " — " + pricing.FormatTokens(total) + " tokens"
+		if cost := pricing.FormatCost(m.detailSession.Usage); cost != "?" {
+			titleText += " · " + cost
+		}
+	}
+	title := detailTitleStyle.Render(titleText)
 	b.WriteString(title)
 	b.WriteString("\n")
 
@@ -210,10 +282,45 @@ func (m Model) detailHelpBar() string {
 			}
 			scroll = dimStyle.Render(fmt.Sprintf("  %d%%", pct))
 		}
-		return helpStyle.Render("  Esc: back  Enter: open  y: yolo  /: search  j/k: scroll") + info + scroll
+		help := "  Esc: back  Enter: open  y: yolo  /: search  j/k: scroll"
+		if len(m.detailItems) > 0 {
+			help += "  Tab: select  Space: expand"
+		}
+		if m.detailHasForks() {
+			help += "  [/]: branch"
+		}
+		if m.detailPaged() {
+			help += "  </>: page"
+		}
+		if m.detailHasThinking() {
+			if m.detailThinking {
+				help += "  t: hide thinking"
+			} else {
+				help += "  t: show thinking"
+			}
+		}
+		return helpStyle.Render(help) + info + scroll
 	}
 }
 
+func (m Model) detailHasForks() bool {
+	for _, msg := range m.detailMessages {
+		if msg.Fork != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (m Model) detailHasThinking() bool {
+	for _, msg := range m.detailMessages {
+		if msg.Thinking != "" {
+			return true
+		}
+	}
+	return false
+}
+
 func (m Model) detailVisibleRows() int {
 	// title bar + bottom bar = 2 lines
 	rows := m.height - 2
@@ -249,63 +356,434 @@ func (m *Model) detailScrollToBottom() {
 	m.detailOffset = maxOffset
 }
 
-// renderDetailContent renders all messages into lines for the viewport.
-func (m Model) renderDetailContent() []string {
-	var lines []string
+// detailItem is an expandable line in the detail view.
+type detailItem struct {
+	key  string // identifies the item across re-renders
+	line int    // index into detailLines
+}
+
+// renderDetail renders all messages into lines for the viewport and records
+// where the expandable items ended up.
+func (m *Model) renderDetail() {
+	m.detailLines = nil
+	m.detailItems = nil
+	m.detailMsgLines = m.detailMsgLines[:0]
 	maxWidth := m.width - 2 // small margin
 	if maxWidth < 40 {
 		maxWidth = 40
 	}
 
-	for _, msg := range m.detailMessages {
-		// role header
-		var header string
-		switch msg.Role {
-		case "user":
-			header = userRoleStyle.Render(pad(" USER", maxWidth))
-		case "assistant":
-			header = assistantRoleStyle.Render(pad(" ASSISTANT", maxWidth))
-		}
-		lines = append(lines, header)
-
-		// message text
-		if msg.Text != "" {
-			textStyle := lipgloss.NewStyle()
-			if msg.Role == "assistant" {
-				textStyle = textStyle.Foreground(lipgloss.Color("250"))
+	if len(m.detailPrev) > 0 {
+		m.detailLines = append(m.detailLines, pageDivider("earlier messages · <: previous page", maxWidth), "")
+	}
+	for mi, msg := range m.detailMessages {
+		m.detailMsgLines = append(m.detailMsgLines, len(m.detailLines))
+		if msg.IsNote() {
+			m.detailLines = append(m.detailLines, " "+dimStyle.Render(msg.Text), "")
+			continue
+		}
+		if msg.Compaction != nil {
+			m.renderCompaction(mi, msg.Compaction, maxWidth)
+			m.detailLines = append(m.detailLines, "")
+			continue
+		}
+		if msg.Fork != nil {
+			m.renderForkItem(mi, msg.Fork)
+		}
+		if msg.Text == "" && len(msg.ToolCalls) == 0 && (!m.detailThinking || msg.Thinking == "") {
+			continue // only reasoning, which is hidden
+		}
+		m.detailLines = append(m.detailLines, renderMessageHeader(msg, maxWidth))
+		if m.detailThinking && msg.Thinking != "" {
+			m.renderThinkingItem(mi, msg.Thinking, maxWidth)
+		}
+		m.detailLines = append(m.detailLines, renderMessageBody(msg, maxWidth)...)
+
+		for ti, tc := range msg.ToolCalls {
+			key := fmt.Sprintf("%d/%d", mi, ti)
+			expanded := m.detailExpanded[key]
+			marker := "▸"
+			if expanded {
+				marker = "▾"
+			}
+			label := marker + " " + toolCallLabel(tc)
+			if tc.Subagent != "" {
+				label += " subagent"
+			}
+			style := toolCallStyle
+			if tc.IsError {
+				style = toolErrorStyle
+			}
+			if len(m.detailItems) == m.detailItemIdx {
+				style = detailCursorStyle
 			}
-			wrapped := wrapText(msg.Text, maxWidth-2)
-			for _, wl := range wrapped {
-				lines = append(lines, " "+textStyle.Render(wl))
+			m.detailItems = append(m.detailItems, detailItem{key: key, line: len(m.detailLines)})
+			m.detailLines = append(m.detailLines, " "+style.Render(label))
+
+			if expanded {
+				if files, ok := toolDiff(tc); ok {
+					m.detailLines = append(m.detailLines, renderDiff(files, maxWidth)...)
+				} else {
+					m.detailLines = append(m.detailLines, renderToolParams(tc, maxWidth)...)
+				}
+				if tc.Subagent != "" {
+					m.detailLines = append(m.detailLines, m.renderSubagent(tc.Subagent, maxWidth)...)
+				} else if tc.HasResult {
+					m.detailLines = append(m.detailLines, renderToolOutput(tc, maxWidth)...)
+				}
 			}
 		}
 
-		// tool calls
-		for _, tc := range msg.ToolCalls {
-			lines = append(lines, " "+toolCallStyle.Render("[Tool: "+tc+"]"))
+		// blank separator
+		m.detailLines = append(m.detailLines, "")
+	}
+
+	switch {
+	case m.detailStream != nil:
+		m.detailLines = append(m.detailLines, " "+dimStyle.Render("Loading more..."))
+	case m.detailNext != nil:
+		m.detailLines = append(m.detailLines, pageDivider("more messages · >: next page", maxWidth))
+	}
+}
+
+// renderForkItem renders the marker of a point where the conversation
+// branched, which switches branches when selected.
+func (m *Model) renderForkItem(mi int, fork *model.Fork) {
+	label := fmt.Sprintf("⑂ Branch %d of %d", fork.Branch+1, len(fork.Leaves))
+	style := forkStyle
+	if len(m.detailItems) == m.detailItemIdx {
+		style = detailCursorStyle
+	}
+	m.detailItems = append(m.detailItems, detailItem{key: fmt.Sprintf("%d/fork", mi), line: len(m.detailLines)})
+	m.detailLines = append(m.detailLines, " "+style.Render(label))
+}
+
+// detailSwitchBranch shows the next (delta 1) or previous (-1) branch at the
+// selected fork, or at the first one on screen if no fork is selected.
+func (m Model) detailSwitchBranch(delta int) (tea.Model, tea.Cmd) {
+	item := -1
+	if m.detailItemIdx >= 0 && strings.HasSuffix(m.detailItems[m.detailItemIdx].key, "/fork") {
+		item = m.detailItemIdx
+	} else {
+		top, bottom := m.detailOffset, m.detailOffset+m.detailVisibleRows()
+		for i, it := range m.detailItems {
+			if strings.HasSuffix(it.key, "/fork") && it.line >= top && it.line < bottom {
+				item = i
+				break
+			}
 		}
+	}
+	if item < 0 {
+		return m, nil
+	}
+	var mi int
+	fmt.Sscanf(m.detailItems[item].key, "%d/", &mi)
+	fork := m.detailMessages[mi].Fork
+	branch := (fork.Branch + delta + len(fork.Leaves)) % len(fork.Leaves)
+
+	// messages before the fork stay the same, so keep the fork where it is
+	m.detailForkAt = m.detailBase + mi
+	m.detailForkRow = m.detailItems[item].line - m.detailOffset
+	m.detailLeaf = fork.Leaves[branch]
+	// reread the page, which the branches share up to the fork, unless the
+	// page starts at the fork itself
+	from := m.detailFrom
+	if from.Index >= m.detailForkAt {
+		from = model.Cursor{}
+		if n := len(m.detailPrev); n > 0 {
+			from = m.detailPrev[n-1]
+			m.detailPrev = m.detailPrev[:n-1]
+		}
+	}
+	return m.loadPage(from)
+}
 
-		// blank separator
-		lines = append(lines, "")
+// focusFork renders a newly loaded branch with the fork at message mi
+// selected and shown at the given screen row.
+func (m *Model) focusFork(mi, row int) {
+	m.detailItemIdx = -1
+	m.renderDetail()
+	key := fmt.Sprintf("%d/fork", mi)
+	for i, it := range m.detailItems {
+		if it.key == key {
+			m.detailItemIdx = i
+			m.renderDetail()
+			m.detailOffset = it.line - row
+			break
+		}
+	}
+	m.detailScrollUp(0)
+	m.detailScrollDown(0)
+	if m.detailSearchQuery != "" {
+		m.computeSearchMatches()
+	}
+}
+
+// renderThinkingItem renders a message's reasoning as a dimmed item that
+// shows its first line until expanded.
+func (m *Model) renderThinkingItem(mi int, thinking string, maxWidth int) {
+	m.renderTextItem(fmt.Sprintf("%d/thinking", mi), "Thinking", thinking, maxWidth)
+}
+
+// renderCompaction renders the divider where the agent compacted its
+// context, followed by the summary it continued from as an item.
+func (m *Model) renderCompaction(mi int, c *model.Compaction, maxWidth int) {
+	m.detailLines = append(m.detailLines, compactionDivider(c, maxWidth))
+	if c.Summary != "" {
+		m.renderTextItem(fmt.Sprintf("%d/summary", mi), "Summary", c.Summary, maxWidth)
+	}
+}
+
+// renderTextItem renders dimmed text under a title, showing only its first
+// line until expanded.
+func (m *Model) renderTextItem(key, title, text string, maxWidth int) {
+	text = strings.TrimSpace(text)
+	expanded := m.detailExpanded[key]
+	first, _, _ := strings.Cut(text, "\n")
+	label := ansi.Truncate("▸ "+title+": "+first, maxWidth-2, "…")
+	if expanded {
+		label = "▾ " + title
+	}
+	style := thinkingStyle
+	if len(m.detailItems) == m.detailItemIdx {
+		style = detailCursorStyle
+	}
+	m.detailItems = append(m.detailItems, detailItem{key: key, line: len(m.detailLines)})
+	m.detailLines = append(m.detailLines, " "+style.Render(label))
+	if !expanded {
+		return
+	}
+	for _, l := range wrapText(text, maxWidth-len([]rune(toolIndent))) {
+		m.detailLines = append(m.detailLines, dimStyle.Render(toolIndent)+thinkingStyle.Render(l))
 	}
+}
+
+// compactionDivider is the full-width rule marking a compaction.
+func compactionDivider(c *model.Compaction, maxWidth int) string {
+	label := " Context compacted here"
+	var details []string
+	if c.Trigger != "" {
+		details = append(details, c.Trigger)
+	}
+	if c.PreTokens > 0 {
+		details = append(details, pricing.FormatTokens(c.PreTokens)+" tokens before")
+	}
+	if len(details) > 0 {
+		label += " (" + strings.Join(details, ", ") + ")"
+	}
+	label += " "
+	rule := maxWidth - ansi.StringWidth(label) - 3
+	if rule < 3 {
+		rule = 3
+	}
+	return " " + compactionStyle.Render("──"+label+strings.Repeat("─", rule))
+}
+
+// renderSubagent renders a subagent conversation indented under its Task call.
+func (m Model) renderSubagent(filePath string, maxWidth int) []string {
+	const indent = "   │ "
+	msgs, loaded := m.detailSubagents[filePath]
+	if !loaded {
+		return []string{dimStyle.Render(indent + "Loading...")}
+	}
+	if len(msgs) == 0 {
+		return []string{dimStyle.Render(indent + "No messages found.")}
+	}
+	var lines []string
+	for _, msg := range msgs {
+		for _, l := range renderMessage(msg, maxWidth-len([]rune(indent))) {
+			lines = append(lines, dimStyle.Render(indent)+l)
+		}
+	}
+	return lines
+}
+
+// renderMessage renders one message: role header, wrapped text, tool calls
+// (one line each) and a trailing blank separator.
+func renderMessage(msg model.Message, maxWidth int) []string {
+	if msg.IsNote() {
+		return []string{" " + dimStyle.Render(msg.Text), ""}
+	}
+	if msg.Compaction != nil {
+		return []string{compactionDivider(msg.Compaction, maxWidth), ""}
+	}
+	if msg.Text == "" && len(msg.ToolCalls) == 0 {
+		return nil // only reasoning, which isn't shown here
+	}
+	lines := renderMessageText(msg, maxWidth)
+
+	// tool calls
+	for _, tc := range msg.ToolCalls {
+		lines = append(lines, " "+toolCallLine(tc))
+	}
+
+	// blank separator
+	lines = append(lines, "")
+	return lines
+}
+
+// renderMessageText renders the role header and wrapped text of a message.
+func renderMessageText(msg model.Message, maxWidth int) []string {
+	return append([]string{renderMessageHeader(msg, maxWidth)}, renderMessageBody(msg, maxWidth)...)
+}
+
+// renderMessageHeader renders the role bar of a message.
+func renderMessageHeader(msg model.Message, maxWidth int) string {
+	var header string
+	switch msg.Role {
+	case "user":
+		header = userRoleStyle.Render(pad(" USER", maxWidth))
+	case "assistant":
+		label := " ASSISTANT"
+		if u := msg.Usage; u.Total() > 0 {
+			// tokens spent on this reply, right-aligned in the header
+			usage := fmt.Sprintf("%s in · %s out ",
+				pricing.FormatTokens(u.Input+u.CacheRead+u.CacheCreation),
+				pricing.FormatTokens(u.Output))
+			if gap := maxWidth - len([]rune(label)) - len([]rune(usage)); gap > 0 {
+				label += strings.Repeat(" ", gap) + usage
+			}
+		}
+		header = assistantRoleStyle.Render(pad(label, maxWidth))
+	}
+	return header
+}
 
+// renderMessageBody renders the text of a message as markdown.
+func renderMessageBody(msg model.Message, maxWidth int) []string {
+	var lines []string
+	if msg.Text != "" {
+		for _, l := range renderMarkdown(msg.Text, maxWidth-2) {
+			lines = append(lines, " "+l)
+		}
+	}
 	return lines
 }
 
-// wrapText splits text into lines that fit within maxWidth.
+// rerenderDetailItems re-renders after items were added or removed,
+// keeping the selected item selected if it is still there.
+func (m *Model) rerenderDetailItems() {
+	selected := ""
+	if m.detailItemIdx >= 0 && m.detailItemIdx < len(m.detailItems) {
+		selected = m.detailItems[m.detailItemIdx].key
+	}
+	m.detailItemIdx = -1
+	m.renderDetail()
+	for i, it := range m.detailItems {
+		if it.key == selected {
+			m.detailItemIdx = i
+			m.renderDetail()
+			break
+		}
+	}
+	m.detailScrollDown(0) // clamp
+	if m.detailSearchQuery != "" {
+		m.computeSearchMatches()
+	}
+}
+
+// detailSelectItem moves the item cursor by delta, wrapping around,
+// and scrolls the selected item into view.
+func (m *Model) detailSelectItem(delta int) {
+	if len(m.detailItems) == 0 {
+		return
+	}
+	if m.detailItemIdx < 0 {
+		// nothing selected yet: start from the first (or last) item on screen
+		m.detailItemIdx = m.firstVisibleItem(delta < 0)
+	} else {
+		m.detailItemIdx = (m.detailItemIdx + delta + len(m.detailItems)) % len(m.detailItems)
+	}
+	m.renderDetail()
+	m.detailScrollToLine(m.detailItems[m.detailItemIdx].line)
+}
+
+func (m Model) firstVisibleItem(last bool) int {
+	top, bottom := m.detailOffset, m.detailOffset+m.detailVisibleRows()
+	found := -1
+	for i, it := range m.detailItems {
+		if it.line >= top && it.line < bottom {
+			found = i
+			if !last {
+				break
+			}
+		}
+	}
+	if found < 0 {
+		return 0
+	}
+	return found
+}
+
+// detailToggleItem expands or collapses the selected item, loading a
+// subagent transcript on first expansion.
+func (m Model) detailToggleItem() (tea.Model, tea.Cmd) {
+	if m.detailItemIdx < 0 || m.detailItemIdx >= len(m.detailItems) {
+		return m, nil
+	}
+	key := m.detailItems[m.detailItemIdx].key
+	if strings.HasSuffix(key, "/fork") {
+		return m.detailSwitchBranch(1)
+	}
+	m.detailExpanded[key] = !m.detailExpanded[key]
+	m.renderDetail()
+	if m.detailSearchQuery != "" {
+		m.computeSearchMatches()
+	}
+	m.detailScrollToLine(m.detailItems[m.detailItemIdx].line)
+
+	if !m.detailExpanded[key] {
+		return m, nil
+	}
+	var mi, ti int
+	if n, _ := fmt.Sscanf(key, "%d/%d", &mi, &ti); n < 2 {
+		return m, nil // not a tool call
+	}
+	path := m.detailMessages[mi].ToolCalls[ti].Subagent
+	if path == "" {
+		return m, nil
+	}
+	if _, loaded := m.detailSubagents[path]; loaded {
+		return m, nil
+	}
+	return m, loadSubagent(m.detailSession, path)
+}
+
+func loadSubagent(parent model.Session, filePath string) tea.Cmd {
+	return func() tea.Msg {
+		var msgs []model.Message
+		for _, child := range parent.Children {
+			if child.FilePath == filePath {
+				msgs = source.ParseMessages(child)
+				break
+			}
+		}
+		return subagentLoadedMsg{parent: parent.FilePath, filePath: filePath, messages: msgs}
+	}
+}
+
+// detailScrollToLine scrolls the minimum amount needed to show line.
+func (m *Model) detailScrollToLine(line int) {
+	visible := m.detailVisibleRows()
+	if line < m.detailOffset {
+		m.detailOffset = line
+	}
+	if line >= m.detailOffset+visible {
+		m.detailOffset = line - visible + 1
+	}
+	m.detailScrollDown(0) // clamp
+}
+
+// wrapText splits text into lines that fit within maxWidth cells, breaking
+// at spaces where possible. Wide (CJK) characters count as two cells.
 func wrapText(text string, maxWidth int) []string {
 	var result []string
 	for _, line := range strings.Split(text, "\n") {
-		if line == "" {
-			result = append(result, "")
+		if ansi.StringWidth(line) <= maxWidth {
+			result = append(result, line)
 			continue
 		}
-		runes := []rune(line)
-		for len(runes) > maxWidth {
-			result = append(result, string(runes[:maxWidth]))
-			runes = runes[maxWidth:]
-		}
-		result = append(result, string(runes))
+		result = append(result, strings.Split(ansi.Wrap(line, maxWidth, ""), "\n")...)
 	}
 	return result
 }
@@ -313,20 +791,30 @@ func wrapText(text string, maxWidth int) []string {
 // Search match support
 
 func (m *Model) computeSearchMatches() {
-	m.detailMatches = nil
 	m.detailMatchIdx = 0
+	m.findSearchMatches()
+	// jump to first match
+	if len(m.detailMatches) > 0 {
+		m.detailScrollToMatch(0)
+	}
+}
+
+// findSearchMatches finds the lines matching the search query without
+// scrolling, keeping the current match if it is still there.
+func (m *Model) findSearchMatches() {
+	m.detailMatches = nil
 	query := strings.ToLower(m.detailSearchQuery)
 	if query == "" {
+		m.detailMatchIdx = 0
 		return
 	}
 	for i, line := range m.detailLines {
-		if strings.Contains(strings.ToLower(line), query) {
+		if strings.Contains(strings.ToLower(ansi.Strip(line)), query) {
 			m.detailMatches = append(m.detailMatches, i)
 		}
 	}
-	// jump to first match
-	if len(m.detailMatches) > 0 {
-		m.detailScrollToMatch(0)
+	if m.detailMatchIdx >= len(m.detailMatches) {
+		m.detailMatchIdx = 0
 	}
 }
 
@@ -376,7 +864,7 @@ func (m Model) highlightLine(line string, lineIdx int) string {
 		}
 	}
 	if isCurrentMatch {
-		return searchHighlightStyle.Render(line)
+		return searchHighlightStyle.Render(ansi.Strip(line))
 	}
 	return line
 }
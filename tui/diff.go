@@ -0,0 +1,248 @@
+package tui
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/jackwu/vibesession/model"
+)
+
+// diffFile is the change a tool call made to one file.
+type diffFile struct {
+	header string     // e.g. "pay/webhook.go" or "Add File: cmd/log.go"
+	hunks  [][]string // lines prefixed with ' ', '-' or '+'
+}
+
+// toolDiff extracts the changes of an edit tool call: Claude's Edit and
+// MultiEdit, Gemini's replace and Codex's apply_patch (called directly or
+// through the shell).
+func toolDiff(tc model.ToolCall) ([]diffFile, bool) {
+	switch tc.Name {
+	case "Edit", "MultiEdit", "replace":
+		var input struct {
+			FilePath     string `json:"file_path"`
+			AbsolutePath string `json:"absolute_path"`
+			OldString    string `json:"old_string"`
+			NewString    string `json:"new_string"`
+			Edits        []struct {
+				OldString string `json:"old_string"`
+				NewString string `json:"new_string"`
+			} `json:"edits"`
+		}
+		if json.Unmarshal([]byte(tc.Input), &input) != nil {
+			return nil, false
+		}
+		file := diffFile{header: input.FilePath}
+		if file.header == "" {
+			file.header = input.AbsolutePath
+		}
+		if input.OldString != "" || input.NewString != "" {
+			file.hunks = append(file.hunks, diffLines(input.OldString, input.NewString))
+		}
+		for _, e := range input.Edits {
+			file.hunks = append(file.hunks, diffLines(e.OldString, e.NewString))
+		}
+		if len(file.hunks) == 0 {
+			return nil, false
+		}
+		return []diffFile{file}, true
+	}
+
+	patch := findPatch(tc.Input)
+	if patch == "" {
+		return nil, false
+	}
+	files := parsePatch(patch)
+	return files, len(files) > 0
+}
+
+// findPatch returns the apply_patch envelope in a tool call's input, which
+// is either the raw patch or JSON arguments with the patch in one of the
+// values.
+func findPatch(input string) string {
+	if params, ok := toolParams(input); ok {
+		for _, p := range params {
+			if patch := findPatch(p.value); patch != "" {
+				return patch
+			}
+		}
+		return ""
+	}
+	start := strings.Index(input, "*** Begin Patch")
+	if start < 0 {
+		return ""
+	}
+	patch := input[start:]
+	if end := strings.Index(patch, "*** End Patch"); end >= 0 {
+		patch = patch[:end]
+	}
+	return patch
+}
+
+// parsePatch splits an apply_patch envelope into files and hunks:
+//
+//	*** Begin Patch
+//	*** Update File: scanner/codex.go
+//	@@ func parse
+//	-old
+//	+new
+//	*** Add File: cmd/log.go
+//	+package main
+//	*** End Patch
+func parsePatch(patch string) []diffFile {
+	var files []diffFile
+	var hunk []string
+	flush := func() {
+		if len(hunk) > 0 && len(files) > 0 {
+			f := &files[len(files)-1]
+			f.hunks = append(f.hunks, hunk)
+		}
+		hunk = nil
+	}
+	for _, line := range strings.Split(patch, "\n") {
+		switch {
+		case strings.HasPrefix(line, "*** Add File: "),
+			strings.HasPrefix(line, "*** Update File: "),
+			strings.HasPrefix(line, "*** Delete File: "):
+			flush()
+			header := strings.TrimPrefix(line, "*** ")
+			if strings.HasPrefix(header, "Update File: ") {
+				header = strings.TrimPrefix(header, "Update File: ")
+			}
+			files = append(files, diffFile{header: strings.TrimSpace(header)})
+		case strings.HasPrefix(line, "*** Move to: "):
+			if len(files) > 0 {
+				files[len(files)-1].header += " → " + strings.TrimSpace(strings.TrimPrefix(line, "*** Move to: "))
+			}
+		case strings.HasPrefix(line, "@@"):
+			flush()
+		case strings.HasPrefix(line, "***"):
+			// Begin/End Patch, End of File
+		case line != "" && strings.ContainsRune(" +-", rune(line[0])):
+			hunk = append(hunk, line)
+		}
+	}
+	flush()
+	return files
+}
+
+// maxDiffCells bounds the line-diff table; larger edits are shown as a
+// plain removal followed by an addition.
+const maxDiffCells = 1 << 20
+
+// diffLines returns a line diff of two strings, each line prefixed with
+// ' ', '-' or '+'.
+func diffLines(old, new string) []string {
+	a, b := splitLines(old), splitLines(new)
+
+	// common prefix and suffix are context
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+
+	var out []string
+	for _, l := range a[:pre] {
+		out = append(out, " "+l)
+	}
+	out = append(out, diffMiddle(a[pre:len(a)-suf], b[pre:len(b)-suf])...)
+	for _, l := range a[len(a)-suf:] {
+		out = append(out, " "+l)
+	}
+	return out
+}
+
+// diffMiddle diffs a and b by longest common subsequence.
+func diffMiddle(a, b []string) []string {
+	var out []string
+	if len(a)*len(b) > maxDiffCells {
+		for _, l := range a {
+			out = append(out, "-"+l)
+		}
+		for _, l := range b {
+			out = append(out, "+"+l)
+		}
+		return out
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			out = append(out, " "+a[i])
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			out = append(out, "-"+a[i])
+			i++
+		default:
+			out = append(out, "+"+b[j])
+			j++
+		}
+	}
+	return out
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// renderDiff renders the changes of an edit tool call in red and green
+// under the call.
+func renderDiff(files []diffFile, maxWidth int) []string {
+	width := maxWidth - len([]rune(toolIndent)) - 1
+	var lines []string
+	add := func(s string) {
+		lines = append(lines, dimStyle.Render(toolIndent)+s)
+	}
+	for _, f := range files {
+		add(diffFileStyle.Render(f.header))
+		for hi, hunk := range f.hunks {
+			if hi > 0 {
+				add(diffHunkStyle.Render("@@"))
+			}
+			for _, l := range hunk {
+				style := dimStyle
+				switch l[0] {
+				case '-':
+					style = diffDelStyle
+				case '+':
+					style = diffAddStyle
+				}
+				// wrapped continuations keep the line's marker column blank
+				for k, wl := range wrapText(l[1:], width) {
+					marker := " "
+					if k == 0 {
+						marker = l[:1]
+					}
+					add(style.Render(marker + wl))
+				}
+			}
+		}
+	}
+	return lines
+}
@@ -0,0 +1,184 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jackwu/vibesession/model"
+	"github.com/jackwu/vibesession/search"
+)
+
+// fullTextLimit caps the results of a full-text search; each one is parsed
+// to extract its snippet.
+const fullTextLimit = 100
+
+// fullTextResultsMsg is sent when a full-text search completes.
+type fullTextResultsMsg struct {
+	query   string
+	results []search.Result
+}
+
+// fullTextIndex brings the search index up to date the first time a search
+// needs it. Searches run as commands, so several can be in flight; they
+// share one build.
+type fullTextIndex struct {
+	once sync.Once
+	ix   *search.Index
+}
+
+func (f *fullTextIndex) get(sessions []model.Session) *search.Index {
+	f.once.Do(func() {
+		f.ix = search.Open()
+		f.ix.Update(sessions)
+		f.ix.Save()
+	})
+	return f.ix
+}
+
+// runFullText searches the index for query, building it on first use.
+func runFullText(f *fullTextIndex, sessions []model.Session, query string) tea.Cmd {
+	return func() tea.Msg {
+		ix := f.get(sessions)
+		return fullTextResultsMsg{query: query, results: ix.Find(sessions, query, fullTextLimit)}
+	}
+}
+
+func (m Model) enterFullText() (Model, tea.Cmd) {
+	m.ftInput.Focus()
+	m.ftInput.CursorEnd()
+	m.mode = modeFullText
+	return m, nil
+}
+
+func (m Model) updateFullTextResults(msg fullTextResultsMsg) Model {
+	m.ftIndexed = true
+	if msg.query != m.ftPending {
+		return m // an earlier search, overtaken by a newer one
+	}
+	// shown even if the input was edited meanwhile; Enter searches the edit
+	m.ftLoading = false
+	m.ftQuery = msg.query
+	m.ftResults = msg.results
+	m.ftCursor = 0
+	m.ftOffset = 0
+	return m
+}
+
+func (m Model) updateFullText(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.ftInput.Blur()
+		m.mode = modeList
+		return m, nil
+
+	case "enter":
+		query := strings.TrimSpace(m.ftInput.Value())
+		if query == "" {
+			return m, nil
+		}
+		// a new query searches; the current one opens the selected result
+		if query != m.ftQuery || len(m.ftResults) == 0 {
+			if m.ftLoading && query == m.ftPending {
+				return m, nil
+			}
+			m.ftLoading = true
+			m.ftPending = query
+			m.ftInput.SetValue(query)
+			return m, runFullText(m.ftIndex, m.sessions, query)
+		}
+		r := m.ftResults[m.ftCursor]
+		m.detailJump = &r
+		m.detailReturn = modeFullText
+		return m.openDetail(r.Session)
+
+	case "up", "ctrl+p":
+		if m.ftCursor > 0 {
+			m.ftCursor--
+			m.clampFullTextOffset()
+		}
+		return m, nil
+
+	case "down", "ctrl+n":
+		if m.ftCursor < len(m.ftResults)-1 {
+			m.ftCursor++
+			m.clampFullTextOffset()
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.ftInput, cmd = m.ftInput.Update(msg)
+	return m, cmd
+}
+
+// fullTextVisible is the number of results on screen; each takes two lines.
+func (m Model) fullTextVisible() int {
+	return max(1, (m.height-3)/2)
+}
+
+func (m *Model) clampFullTextOffset() {
+	visible := m.fullTextVisible()
+	if m.ftCursor < m.ftOffset {
+		m.ftOffset = m.ftCursor
+	}
+	if m.ftCursor >= m.ftOffset+visible {
+		m.ftOffset = m.ftCursor - visible + 1
+	}
+}
+
+func (m Model) viewFullText() string {
+	var b strings.Builder
+
+	title := titleStyle.Render("VibeSession")
+	info := "  full-text search"
+	switch {
+	case m.ftLoading && !m.ftIndexed:
+		info += " — indexing sessions..."
+	case m.ftLoading:
+		info += " — searching..."
+	case m.ftQuery != "":
+		info += fmt.Sprintf(" — %d sessions", len(m.ftResults))
+		if len(m.ftResults) == fullTextLimit {
+			info += " (first " + fmt.Sprint(fullTextLimit) + ")"
+		}
+	}
+	b.WriteString(title + dimStyle.Render(info) + "\n")
+	b.WriteString(m.renderHeader() + "\n")
+
+	lines := 0
+	end := min(len(m.ftResults), m.ftOffset+m.fullTextVisible())
+	for i := m.ftOffset; i < end; i++ {
+		r := m.ftResults[i]
+		b.WriteString(m.renderRow(r.Session, i == m.ftCursor) + "\n")
+		b.WriteString("  " + renderSnippet(r.Snippet, r.Term, m.width-4) + "\n")
+		lines += 2
+	}
+	if m.ftQuery != "" && len(m.ftResults) == 0 && !m.ftLoading {
+		b.WriteString(dimStyle.Render("  No matches.") + "\n")
+		lines++
+	}
+	for ; lines < m.height-3; lines++ {
+		b.WriteString("\n")
+	}
+
+	b.WriteString(statusBarStyle.Render("Find: ") + m.ftInput.View())
+	return b.String()
+}
+
+// renderSnippet dims the snippet and highlights the matched term.
+func renderSnippet(snippet, term string, width int) string {
+	runes := []rune(snippet)
+	if len(runes) > width {
+		snippet = string(runes[:max(0, width-1)]) + "…"
+	}
+	if term == "" {
+		return dimStyle.Render(snippet)
+	}
+	before, after, ok := strings.Cut(snippet, term)
+	if !ok {
+		return dimStyle.Render(snippet)
+	}
+	return dimStyle.Render(before) + snippetMatchStyle.Render(term) + dimStyle.Render(after)
+}
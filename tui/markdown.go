@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Rendering markdown is far slower than the rest of the detail view, which
+// is re-rendered on every selection change, so results are cached.
+const maxMarkdownCache = 4096
+
+var markdown struct {
+	sync.Mutex
+	renderers map[int]*glamour.TermRenderer // by wrap width
+	cache     map[markdownKey][]string
+}
+
+var trailingPadRe = regexp.MustCompile(`(?:\x1b\[[0-9;]*m| )+$`)
+
+type markdownKey struct {
+	text  string
+	width int
+}
+
+// renderMarkdown renders message text as terminal markdown (headings,
+// lists, tables, syntax-highlighted code fences) in lines no wider than
+// width cells.
+func renderMarkdown(text string, width int) []string {
+	markdown.Lock()
+	defer markdown.Unlock()
+
+	key := markdownKey{text, width}
+	if lines, ok := markdown.cache[key]; ok {
+		return lines
+	}
+	r, err := markdownRenderer(width)
+	if err != nil {
+		return wrapText(text, width)
+	}
+	// tabs would throw off the width of code blocks
+	out, err := r.Render(strings.ReplaceAll(text, "\t", "    "))
+	if err != nil {
+		return wrapText(text, width)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(strings.Trim(out, "\n"), "\n") {
+		// drop the styled padding the renderer fills lines up to width with
+		line = trailingPadRe.ReplaceAllString(line, "\x1b[0m")
+		// code and unbroken CJK runs aren't wrapped by the renderer
+		if ansi.StringWidth(line) > width {
+			lines = append(lines, strings.Split(ansi.Hardwrap(line, width, true), "\n")...)
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	if len(markdown.cache) >= maxMarkdownCache {
+		markdown.cache = nil
+	}
+	if markdown.cache == nil {
+		markdown.cache = make(map[markdownKey][]string)
+	}
+	markdown.cache[key] = lines
+	return lines
+}
+
+func markdownRenderer(width int) (*glamour.TermRenderer, error) {
+	if r, ok := markdown.renderers[width]; ok {
+		return r, nil
+	}
+	// dark like the rest of the TUI, without the margins and blank lines
+	// around the document: the detail view adds its own
+	style := styles.DarkStyleConfig
+	var noMargin uint
+	style.Document.Margin = &noMargin
+	style.Document.BlockPrefix = ""
+	style.Document.BlockSuffix = ""
+
+	r, err := glamour.NewTermRenderer(
+		glamour.WithStyles(style),
+		glamour.WithColorProfile(lipgloss.ColorProfile()),
+		glamour.WithWordWrap(width),
+		glamour.WithPreservedNewLines(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if markdown.renderers == nil {
+		markdown.renderers = make(map[int]*glamour.TermRenderer)
+	}
+	markdown.renderers[width] = r
+	return r, nil
+}
@@ -8,6 +8,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/jackwu/vibesession/launcher"
+	"github.com/jackwu/vibesession/source"
 )
 
 // newForm field indices
@@ -19,7 +20,7 @@ const (
 )
 
 type newForm struct {
-	tool    int // 0 = claude, 1 = codex
+	tool    int // index into source.All()
 	dirInput textinput.Model
 	mode    int // 0 = normal, 1 = yolo
 	focus   int // which field is focused
@@ -82,16 +83,16 @@ func (m Model) updateNewForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case "enter":
-		tool := "claude"
-		if f.tool == 1 {
-			tool = "codex"
+		providers := source.All()
+		if f.tool >= len(providers) {
+			return m, nil
 		}
 		dir := f.dirInput.Value()
 		if dir == "" {
 			dir = m.cwd
 		}
 		yolo := f.mode == 1
-		cmd := launcher.BuildNewCommand(tool, dir, yolo)
+		cmd := launcher.BuildNewCommand(providers[f.tool].Source(), dir, yolo)
 		if cmd != "" {
 			m.launchCmd = cmd
 			m.quitting = true
@@ -105,9 +106,13 @@ func (m Model) updateNewForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case fieldTool:
 		switch key {
 		case "left", "h":
-			f.tool = 0
+			if f.tool > 0 {
+				f.tool--
+			}
 		case "right", "l":
-			f.tool = 1
+			if f.tool < len(source.All())-1 {
+				f.tool++
+			}
 		}
 	case fieldDir:
 		var cmd tea.Cmd
@@ -146,7 +151,7 @@ func (m Model) viewNewForm() string {
 		Border(lipgloss.RoundedBorder()).
 		BorderForeground(lipgloss.Color("39")).
 		Padding(1, 2).
-		Width(56)
+		Width(68)
 
 	titleStr := lipgloss.NewStyle().
 		Bold(true).
@@ -155,7 +160,11 @@ func (m Model) viewNewForm() string {
 
 	// Tool field
 	toolLabel := m.fieldLabel("Tool:", f.focus == fieldTool)
-	toolValue := m.renderRadio([]string{"Claude Code", "Codex"}, f.tool, f.focus == fieldTool)
+	var toolNames []string
+	for _, p := range source.All() {
+		toolNames = append(toolNames, p.Label())
+	}
+	toolValue := m.renderRadio(toolNames, f.tool, f.focus == fieldTool)
 
 	// Dir field
 	dirLabel := m.fieldLabel("Dir:", f.focus == fieldDir)
@@ -0,0 +1,198 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/jackwu/vibesession/model"
+	"github.com/jackwu/vibesession/source"
+)
+
+// detailPageSize is how many messages the conversation viewer keeps loaded.
+// Longer conversations are shown a page at a time.
+const detailPageSize = 1000
+
+// messageStream reads a page of a conversation in the background and hands
+// it over a batch at a time.
+type messageStream struct {
+	batches chan messageBatchMsg
+	stop    chan struct{}
+	once    sync.Once
+}
+
+// messageBatchMsg is sent for each batch of messages a stream reads.
+type messageBatchMsg struct {
+	stream   *messageStream
+	messages []model.Message
+	next     *model.Cursor // where the messages after these start, nil at the end
+}
+
+// startStream starts reading the branch of s ending at leaf from the
+// cursor from.
+func startStream(s model.Session, leaf string, from model.Cursor) *messageStream {
+	st := &messageStream{batches: make(chan messageBatchMsg), stop: make(chan struct{})}
+	go func() {
+		defer close(st.batches)
+		source.StreamMessages(s, leaf, from, func(msgs []model.Message, next *model.Cursor) bool {
+			select {
+			case st.batches <- messageBatchMsg{stream: st, messages: msgs, next: next}:
+				return true
+			case <-st.stop:
+				return false
+			}
+		})
+	}()
+	return st
+}
+
+// wait returns a command that delivers the next batch.
+func (st *messageStream) wait() tea.Cmd {
+	return func() tea.Msg {
+		if b, ok := <-st.batches; ok {
+			return b
+		}
+		return nil
+	}
+}
+
+// close stops reading; batches not delivered yet are dropped.
+func (st *messageStream) close() {
+	st.once.Do(func() { close(st.stop) })
+}
+
+func (m *Model) stopDetailStream() {
+	if m.detailStream != nil {
+		m.detailStream.close()
+		m.detailStream = nil
+	}
+}
+
+// loadPage starts reading the page of the conversation that begins at from.
+func (m Model) loadPage(from model.Cursor) (Model, tea.Cmd) {
+	m.stopDetailStream()
+	m.detailStream = startStream(m.detailSession, m.detailLeaf, from)
+	m.detailFrom = from
+	m.detailBase = from.Index
+	m.detailNext = nil
+	m.detailMessages = nil
+	m.detailLines = nil
+	m.detailOffset = 0
+	m.detailLoading = true
+	m.detailMatches = nil
+	m.detailMatchIdx = 0
+	m.detailItems = nil
+	m.detailItemIdx = -1
+	m.detailExpanded = make(map[string]bool)
+	return m, m.detailStream.wait()
+}
+
+// detailTarget returns the message that has to be loaded before the view
+// settles: the full-text hit to jump to or the fork whose branch was
+// switched. It returns -1 if there is none.
+func (m Model) detailTarget() int {
+	switch {
+	case m.detailForkAt >= 0:
+		return m.detailForkAt
+	case m.detailJump != nil:
+		return m.detailJump.Message
+	}
+	return -1
+}
+
+// updateDetailBatch adds a batch of messages to the page being read. Once
+// the page is full reading stops; pages before the target are skipped.
+func (m Model) updateDetailBatch(msg messageBatchMsg) (Model, tea.Cmd) {
+	if msg.stream != m.detailStream || m.detailStream == nil {
+		return m, nil // a page that is no longer shown
+	}
+	m.detailMessages = append(m.detailMessages, msg.messages...)
+	m.detailNext = msg.next
+	end := m.detailBase + len(m.detailMessages)
+	full := len(m.detailMessages) >= detailPageSize
+
+	if full && msg.next != nil && m.detailTarget() >= end {
+		m.detailPrev = append(m.detailPrev, m.detailFrom)
+		m.detailFrom, m.detailBase = *msg.next, msg.next.Index
+		m.detailMessages = nil
+		return m, m.detailStream.wait()
+	}
+
+	var cmd tea.Cmd
+	if msg.next == nil || full {
+		m.stopDetailStream()
+	} else {
+		cmd = m.detailStream.wait()
+	}
+	if m.detailTarget() >= end && m.detailStream != nil {
+		return m, cmd // keep reading until the target is in
+	}
+	m.showDetailLoaded()
+	return m, cmd
+}
+
+// showDetailLoaded renders the messages read so far: on the first batch
+// from the top or at the target, afterwards keeping the scroll position.
+func (m *Model) showDetailLoaded() {
+	first := m.detailLoading
+	m.detailLoading = false
+	switch {
+	case m.detailForkAt >= 0:
+		m.focusFork(m.detailForkAt-m.detailBase, m.detailForkRow)
+		m.detailForkAt = -1
+	case first:
+		m.renderDetail()
+		m.detailOffset = 0
+		m.findSearchMatches()
+		if m.detailJump != nil {
+			m.jumpToHit(*m.detailJump)
+			m.detailJump = nil
+		}
+	default:
+		m.renderDetail()
+		m.detailScrollDown(0) // clamp
+		m.findSearchMatches()
+	}
+}
+
+// detailPaged reports whether the conversation is longer than a page.
+func (m Model) detailPaged() bool {
+	return len(m.detailPrev) > 0 || (m.detailNext != nil && m.detailStream == nil)
+}
+
+// detailNextPage shows the page after the loaded one, once it is complete.
+func (m Model) detailNextPage() (tea.Model, tea.Cmd) {
+	if m.detailNext == nil || m.detailStream != nil {
+		return m, nil
+	}
+	m.detailPrev = append(m.detailPrev, m.detailFrom)
+	return m.loadPage(*m.detailNext)
+}
+
+// detailPrevPage shows the page before the loaded one.
+func (m Model) detailPrevPage() (tea.Model, tea.Cmd) {
+	n := len(m.detailPrev)
+	if n == 0 {
+		return m, nil
+	}
+	from := m.detailPrev[n-1]
+	m.detailPrev = m.detailPrev[:n-1]
+	return m.loadPage(from)
+}
+
+// pageDivider is the rule shown where the loaded page ends.
+func pageDivider(label string, maxWidth int) string {
+	label = " " + label + " "
+	rule := maxWidth - ansi.StringWidth(label) - 3
+	if rule < 3 {
+		rule = 3
+	}
+	return " " + dimStyle.Render("──"+label+strings.Repeat("─", rule))
+}
+
+// detailPageRange describes the loaded messages, e.g. "msgs 1001–2000".
+func (m Model) detailPageRange() string {
+	return fmt.Sprintf("msgs %d–%d", m.detailBase+1, m.detailBase+len(m.detailMessages))
+}
@@ -1,6 +1,10 @@
 package tui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jackwu/vibesession/model"
+	"github.com/jackwu/vibesession/source"
+)
 
 var (
 	titleStyle = lipgloss.NewStyle().
@@ -19,16 +23,24 @@ var (
 			Foreground(lipgloss.Color("255")).
 			Padding(0, 1)
 
-	normalStyle = lipgloss.NewStyle().
-			Padding(0, 1)
+	// selectedTextStyle is selectedStyle without padding, for rendering a
+	// selected row piece by piece
+	selectedTextStyle = lipgloss.NewStyle().
+				Background(lipgloss.Color("25")).
+				Foreground(lipgloss.Color("255"))
 
-	claudeTag = lipgloss.NewStyle().
+	// characters matched by the list search
+	matchStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("214")).
 			Bold(true)
 
-	codexTag = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("42")).
-			Bold(true)
+	selectedMatchStyle = lipgloss.NewStyle().
+				Background(lipgloss.Color("25")).
+				Foreground(lipgloss.Color("214")).
+				Bold(true)
+
+	normalStyle = lipgloss.NewStyle().
+			Padding(0, 1)
 
 	dimStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("242"))
@@ -61,6 +73,49 @@ var (
 			Foreground(lipgloss.Color("242")).
 			Italic(true)
 
+	// tool calls that failed
+	toolErrorStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("203")).
+			Italic(true)
+
+	// parameters of an expanded tool call
+	toolParamNameStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("110"))
+
+	toolParamStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("250"))
+
+	// diffs of edit tool calls
+	diffFileStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("255")).
+			Bold(true)
+
+	diffHunkStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("37"))
+
+	diffAddStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("114"))
+
+	diffDelStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("203"))
+
+	// reasoning blocks, shown on request
+	thinkingStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("240")).
+			Italic(true)
+
+	// points where a conversation branched
+	forkStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("141"))
+
+	// where the agent compacted its context
+	compactionStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("179"))
+
+	detailCursorStyle = lipgloss.NewStyle().
+				Background(lipgloss.Color("25")).
+				Foreground(lipgloss.Color("255"))
+
 	detailTitleStyle = lipgloss.NewStyle().
 				Bold(true).
 				Foreground(lipgloss.Color("255")).
@@ -70,4 +125,24 @@ var (
 	searchHighlightStyle = lipgloss.NewStyle().
 				Background(lipgloss.Color("226")).
 				Foreground(lipgloss.Color("0"))
+
+	errorStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("203"))
+
+	// problems worth a look that didn't stop anything
+	warningStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("179"))
+
+	snippetMatchStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("229")).
+				Bold(true)
 )
+
+// sourceTag returns the style for a session's source column, colored by its provider.
+func sourceTag(src model.Source) lipgloss.Style {
+	style := lipgloss.NewStyle().Bold(true)
+	if p := source.Lookup(src); p != nil {
+		style = style.Foreground(lipgloss.Color(p.Color()))
+	}
+	return style
+}
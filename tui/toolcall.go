@@ -0,0 +1,134 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/jackwu/vibesession/model"
+)
+
+// toolIndent prefixes the lines shown under an expanded tool call.
+const toolIndent = "   │ "
+
+// toolCallLabel is the one-line description of a tool call, marked when
+// the call failed.
+func toolCallLabel(tc model.ToolCall) string {
+	label := "[Tool: " + tc.Summary + "]"
+	if tc.IsError {
+		label = "✗ " + label
+	}
+	return label
+}
+
+// toolCallLine renders a tool call that can't be expanded.
+func toolCallLine(tc model.ToolCall) string {
+	if tc.IsError {
+		return toolErrorStyle.Render(toolCallLabel(tc))
+	}
+	return toolCallStyle.Render(toolCallLabel(tc))
+}
+
+// renderToolParams renders the full input of a tool call, one parameter
+// per line and multi-line values (a Write's content, a patch) below their
+// name.
+func renderToolParams(tc model.ToolCall, maxWidth int) []string {
+	width := maxWidth - len([]rune(toolIndent))
+	params, ok := toolParams(tc.Input)
+	if !ok {
+		// free-form input, like a Codex patch or shell command line
+		params = []toolParam{{value: tc.Input}}
+	}
+	var lines []string
+	add := func(s string) {
+		lines = append(lines, dimStyle.Render(toolIndent)+s)
+	}
+	for _, p := range params {
+		if p.name == "" {
+			for _, l := range wrapText(p.value, width) {
+				add(toolParamStyle.Render(l))
+			}
+			continue
+		}
+		name := toolParamNameStyle.Render(p.name + ":")
+		if !strings.Contains(p.value, "\n") && len([]rune(p.name))+2+len([]rune(p.value)) <= width {
+			add(name + " " + toolParamStyle.Render(p.value))
+			continue
+		}
+		add(name)
+		for _, l := range wrapText(p.value, width-2) {
+			add("  " + toolParamStyle.Render(l))
+		}
+	}
+	if len(lines) == 0 {
+		add(dimStyle.Render("(no parameters)"))
+	}
+	return lines
+}
+
+// renderToolOutput renders the result of a tool call indented under it,
+// in red when the call failed.
+func renderToolOutput(tc model.ToolCall, maxWidth int) []string {
+	style := dimStyle
+	if tc.IsError {
+		style = errorStyle
+	}
+	output := tc.Output
+	if output == "" {
+		output = "(no output)"
+	}
+	lines := []string{dimStyle.Render(toolIndent + "── output ──")}
+	for _, l := range wrapText(output, maxWidth-len([]rune(toolIndent))) {
+		lines = append(lines, dimStyle.Render(toolIndent)+style.Render(l))
+	}
+	return lines
+}
+
+// toolParam is one parameter of a tool call's input.
+type toolParam struct {
+	name  string // "" for input that isn't a JSON object
+	value string
+}
+
+// toolParams splits a JSON object input into its parameters, in the order
+// they were written. Strings are shown unquoted.
+func toolParams(input string) ([]toolParam, bool) {
+	dec := json.NewDecoder(strings.NewReader(input))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, false
+	}
+	var params []toolParam
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, false
+		}
+		name, _ := tok.(string)
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, false
+		}
+		params = append(params, toolParam{name: name, value: paramValue(raw)})
+	}
+	return params, true
+}
+
+func paramValue(raw json.RawMessage) string {
+	var str string
+	if json.Unmarshal(raw, &str) == nil {
+		return str
+	}
+	// shell commands are often argv arrays like ["bash", "-lc", "go test"]
+	var argv []string
+	if json.Unmarshal(raw, &argv) == nil {
+		if len(argv) == 3 && (argv[1] == "-lc" || argv[1] == "-c") {
+			return argv[2]
+		}
+		return strings.Join(argv, " ")
+	}
+	var buf bytes.Buffer
+	if json.Indent(&buf, raw, "", "  ") != nil {
+		return string(raw)
+	}
+	return buf.String()
+}